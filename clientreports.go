@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// clientReports adds up the discarded events of all client reports in the
+// given files, or in the .envelope files under the given directories, and
+// returns the exit status: 1 if any file could not be read or is damaged, 0
+// otherwise.
+func clientReports(w io.Writer, paths []string) int {
+	status := 0
+	var scanned, files, reports int
+	var discarded []event.DiscardedEvent
+	for _, path := range expandPaths(w, paths, &status) {
+		env, diags, _, err := readEnvelope(path)
+		if err != nil {
+			fmt.Fprintf(w, "%s: error: %v\n", path, err)
+			status = 1
+			continue
+		}
+		for _, d := range diags {
+			fmt.Fprintf(w, "%s: error: %v [%s]\n", path, d, d.Kind)
+			status = 1
+		}
+		scanned++
+		found := false
+		for i, item := range env.Items {
+			if item.Type != "client_report" {
+				continue
+			}
+			r, err := item.ClientReport()
+			if err != nil {
+				fmt.Fprintf(w, "%s: item %d: error: %v\n", path, i+1, err)
+				status = 1
+				continue
+			}
+			discarded = append(discarded, r.DiscardedEvents...)
+			reports++
+			found = true
+		}
+		if found {
+			files++
+		}
+	}
+
+	sums := event.SumDiscardedEvents(discarded)
+	total := 0
+	for _, d := range sums {
+		total += d.Quantity
+	}
+	width := max(len("QUANTITY"), len(fmt.Sprint(total)))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "REASON\tCATEGORY\t%*s\n", width, "QUANTITY")
+	for _, d := range sums {
+		fmt.Fprintf(tw, "%s\t%s\t%*d\n", d.Reason, d.Category, width, d.Quantity)
+	}
+	fmt.Fprintf(tw, "\ttotal\t%*d\n", width, total)
+	tw.Flush()
+	fmt.Fprintf(w, "%d client reports in %d of %d files\n", reports, files, scanned)
+	return status
+}
+
+// expandPaths replaces directories with the .envelope files found under
+// them.
+func expandPaths(w io.Writer, paths []string, status *int) []string {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			switch {
+			case err != nil:
+				return err
+			case p == path && !d.IsDir():
+				files = append(files, p)
+			case !d.IsDir() && filepath.Ext(p) == ".envelope":
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(w, "%s: error: %v\n", path, err)
+			*status = 1
+		}
+	}
+	return files
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClientReports(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+	os.WriteFile(filepath.Join(dir, "a.envelope"), []byte("{}\n{\"type\":\"client_report\"}\n"+
+		`{"discarded_events":[{"reason":"queue_overflow","category":"error","quantity":12},{"reason":"ratelimit_backoff","category":"transaction","quantity":3}]}`+"\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "sub", "b.envelope"), []byte("{}\n{\"type\":\"client_report\"}\n"+
+		`{"discarded_events":[{"reason":"queue_overflow","category":"error","quantity":1}]}`+"\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "c.envelope"), []byte("{}\n{\"type\":\"event\"}\n{}\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an envelope"), 0o644)
+
+	var out bytes.Buffer
+	if status := clientReports(&out, []string{dir}); status != 0 {
+		t.Errorf("status = %d, want 0, output:\n%s", status, out.String())
+	}
+	want := "REASON             CATEGORY     QUANTITY\n" +
+		"queue_overflow     error              13\n" +
+		"ratelimit_backoff  transaction         3\n" +
+		"                   total              16\n" +
+		"2 client reports in 2 of 3 files\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if status := clientReports(&out, []string{filepath.Join(dir, "missing.envelope")}); status != 1 {
+		t.Errorf("missing: status = %d, want 1", status)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("missing.envelope: error:")) || !bytes.Contains(out.Bytes(), []byte("0 client reports in 0 of 0 files")) {
+		t.Errorf("missing: output:\n%s", out.String())
+	}
+}
+
+func TestClientReportsDamaged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "damaged.envelope")
+	os.WriteFile(path, []byte("{}\n{\"type\":\"client_report\",\"length\":100}\n{}\n"), 0o644)
+
+	var out bytes.Buffer
+	if status := clientReports(&out, []string{path}); status != 1 {
+		t.Errorf("status = %d, want 1", status)
+	}
+	if !strings.Contains(out.String(), "[truncated_payload]") {
+		t.Errorf("output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// debugImages lists the debug images of the events in the given files and
+// whether a debug file for each is found in the directories given with
+// --debug-dir, a mapping file among those given with --mapping, or a source
+// map in the directories given with --source-maps. Images that recur across
+// events are listed once. It returns the exit status: 1 if any file could
+// not be read or is damaged, or any image has no debug file, 0 otherwise.
+func debugImages(w io.Writer, args []string) int {
+	fs := flag.NewFlagSet("debug-images", flag.ContinueOnError)
+	fs.SetOutput(w)
+	fs.Usage = func() { fmt.Fprint(w, usage) }
+	s := symbolicatorFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 1
+	}
+
+	status := 0
+	var images, missing int
+	seen := map[[3]string]bool{}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CODE FILE\tCODE ID\tDEBUG ID\tARCH\tIMAGE ADDR\tSIZE\tDEBUG FILE")
+	for _, path := range fs.Args() {
+		env, diags, _, err := readEnvelope(path)
+		if err != nil {
+			tw.Flush()
+			fmt.Fprintf(w, "%s: error: %v\n", path, err)
+			status = 1
+			continue
+		}
+		if len(diags) > 0 {
+			tw.Flush()
+		}
+		for _, d := range diags {
+			fmt.Fprintf(w, "%s: error: %v [%s]\n", path, d, d.Kind)
+			status = 1
+		}
+		for i, item := range env.Items {
+			if item.Type != "event" && item.Type != "transaction" {
+				continue
+			}
+			ev, err := item.Event()
+			if err != nil {
+				tw.Flush()
+				fmt.Fprintf(w, "%s: item %d: error: %v\n", path, i+1, err)
+				status = 1
+				continue
+			}
+			if ev.DebugMeta == nil {
+				continue
+			}
+			for _, img := range ev.DebugMeta.Images {
+				key := [3]string{img.CodeFile, img.CodeID, img.DebugID}
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				debugFile := "-"
+				if s.Enabled() {
+					p, err := s.Locate(img)
+					switch {
+					case err != nil:
+						tw.Flush()
+						fmt.Fprintf(w, "error: %v\n", err)
+						return 1
+					case p == "":
+						debugFile = "missing"
+						missing++
+						status = 1
+					default:
+						debugFile = p
+					}
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", orDash(img.CodeFile), orDash(img.CodeID),
+					orDash(cmp.Or(img.DebugID, img.UUID)), orDash(img.Arch), orDash(img.ImageAddr), img.ImageSize, debugFile)
+				images++
+			}
+		}
+	}
+	tw.Flush()
+	if s.Enabled() {
+		fmt.Fprintf(w, "%d images, %d without debug files\n", images, missing)
+	} else {
+		fmt.Fprintf(w, "%d images\n", images)
+	}
+	return status
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDebugImages(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "crash.envelope")
+	ev := `{"debug_meta":{"images":[` +
+		`{"type":"elf","code_file":"/usr/bin/crash","code_id":"89a79abce0b8c4c8e628e26bc0b8e10f38a90b1a","arch":"x86_64","image_addr":"0x10000","image_size":16384},` +
+		`{"type":"elf","code_file":"/usr/lib/libc.so.6","code_id":"00112233445566778899aabbccddeeff00112233","image_addr":"0x7f0000000000","image_size":65536}]}}`
+	item := fmt.Sprintf("{\"type\":\"event\",\"length\":%d}\n%s\n", len(ev), ev)
+	os.WriteFile(path, []byte("{}\n"+item+item), 0o644)
+
+	var out bytes.Buffer
+	if status := debugImages(&out, []string{path}); status != 0 {
+		t.Errorf("without --debug-dir: status = %d, want 0, output:\n%s", status, out.String())
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || !strings.HasSuffix(lines[1], "16384  -") || lines[3] != "2 images" {
+		t.Errorf("without --debug-dir: output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if status := debugImages(&out, []string{"--debug-dir", "symbolicate/testdata", path}); status != 1 {
+		t.Errorf("status = %d, want 1", status)
+	}
+	lines = strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("output lines = %d, want 4:\n%s", len(lines), out.String())
+	}
+	for i, want := range []string{"DEBUG FILE", "symbolicate/testdata/crash", "missing", "2 images, 1 without debug files"} {
+		if !strings.HasSuffix(lines[i], want) {
+			t.Errorf("line %d = %q, want suffix %q", i, lines[i], want)
+		}
+	}
+	if !strings.Contains(lines[1], "/usr/bin/crash") || !strings.Contains(lines[1], "x86_64") || !strings.Contains(lines[1], "0x10000") {
+		t.Errorf("line 1 = %q", lines[1])
+	}
+}
+
+func TestDebugImagesDamaged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "damaged.envelope")
+	os.WriteFile(path, []byte("{}\n{\"type\":\"event\",\"length\":100}\n{}\n"), 0o644)
+
+	var out bytes.Buffer
+	if status := debugImages(&out, []string{path}); status != 1 {
+		t.Errorf("status = %d, want 1", status)
+	}
+	if !strings.Contains(out.String(), "[truncated_payload]") {
+		t.Errorf("output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,79 @@
+package envelope
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// New returns an envelope without items whose header carries eventID, if
+// not empty.
+func New(eventID string) *Envelope {
+	env := &Envelope{Header: json.RawMessage(`{}`)}
+	env.SetHeader(&Header{EventID: eventID})
+	return env
+}
+
+// Add appends items to the envelope and returns it for chaining.
+func (env *Envelope) Add(items ...Item) *Envelope {
+	env.Items = append(env.Items, items...)
+	return env
+}
+
+// NewEventItem returns an event item. The event can be given as encoded JSON
+// ([]byte or json.RawMessage) or as any value to be marshaled, and likewise
+// for the other JSON item constructors.
+func NewEventItem(event any) (Item, error) {
+	return newJSONItem("event", event)
+}
+
+func NewTransactionItem(transaction any) (Item, error) {
+	return newJSONItem("transaction", transaction)
+}
+
+func NewSessionItem(session any) (Item, error) {
+	return newJSONItem("session", session)
+}
+
+func NewUserReportItem(report any) (Item, error) {
+	return newJSONItem("user_report", report)
+}
+
+// NewAttachmentItem returns an attachment item. The content and attachment
+// types are optional.
+func NewAttachmentItem(filename, contentType, attachmentType string, data []byte) Item {
+	// Setting plain values on a fresh header cannot fail
+	item := Item{Payload: data}
+	item.Set("type", "attachment")
+	item.Set("length", len(data))
+	item.Set("filename", filename)
+	if contentType != "" {
+		item.Set("content_type", contentType)
+	}
+	if attachmentType != "" {
+		item.Set("attachment_type", attachmentType)
+	}
+	return item
+}
+
+func newJSONItem(typ string, v any) (Item, error) {
+	var payload []byte
+	switch v := v.(type) {
+	case []byte:
+		payload = v
+	case json.RawMessage:
+		payload = v
+	default:
+		var err error
+		if payload, err = json.Marshal(v); err != nil {
+			return Item{}, fmt.Errorf("encoding %s: %w", typ, err)
+		}
+	}
+	if !json.Valid(payload) {
+		return Item{}, fmt.Errorf("invalid JSON in %s payload", typ)
+	}
+
+	item := Item{Payload: payload}
+	item.Set("type", typ)
+	item.Set("length", len(payload))
+	return item, nil
+}
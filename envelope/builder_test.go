@@ -0,0 +1,79 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	const id = "9ec79c33ec9942ab8353589fcb2e04dc"
+	event, err := NewEventItem(map[string]any{"event_id": id, "message": "hello"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	session, err := NewSessionItem(json.RawMessage(`{"sid":"` + id + `","status":"ok","attrs":{"release":"app@1.0"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, err := NewUserReportItem(struct {
+		EventID  string `json:"event_id"`
+		Comments string `json:"comments"`
+	}{id, "it broke"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transaction, err := NewTransactionItem([]byte(`{"type":"transaction"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	attachment := NewAttachmentItem("dump.dmp", "application/octet-stream", "event.minidump", []byte{0x4d, 0x44, 0x4d, 0x50})
+
+	env := New(id).Add(event, session).Add(report, transaction, attachment)
+
+	var buf bytes.Buffer
+	if err := env.Serialize(&buf); err != nil {
+		t.Fatalf("serialize error: %v", err)
+	}
+	want := `{"event_id":"` + id + `"}` + "\n" +
+		`{"type":"event","length":65}` + "\n" +
+		`{"event_id":"` + id + `","message":"hello"}` + "\n" +
+		`{"type":"session","length":86}` + "\n" +
+		`{"sid":"` + id + `","status":"ok","attrs":{"release":"app@1.0"}}` + "\n" +
+		`{"type":"user_report","length":69}` + "\n" +
+		`{"event_id":"` + id + `","comments":"it broke"}` + "\n" +
+		`{"type":"transaction","length":22}` + "\n" +
+		`{"type":"transaction"}` + "\n" +
+		`{"type":"attachment","length":4,"filename":"dump.dmp","content_type":"application/octet-stream","attachment_type":"event.minidump"}` + "\n" +
+		"MDMP\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	if findings := Validate(env); len(findings) != 1 || findings[0].Rule != "duplicate-event" {
+		t.Errorf("findings = %v, want duplicate-event only", findings)
+	}
+	if env.Items[4].Type != "attachment" || env.Items[4].Filename != "dump.dmp" {
+		t.Errorf("attachment type/filename = %q/%q", env.Items[4].Type, env.Items[4].Filename)
+	}
+}
+
+func TestBuilderEmpty(t *testing.T) {
+	env := New("")
+	if string(env.Header) != `{}` {
+		t.Errorf("header = %s, want {}", env.Header)
+	}
+	item := NewAttachmentItem("a.txt", "", "", nil)
+	if string(item.Header) != `{"type":"attachment","length":0,"filename":"a.txt"}` {
+		t.Errorf("header = %s", item.Header)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	if _, err := NewEventItem([]byte("not json")); err == nil {
+		t.Error("invalid JSON: expected error, got nil")
+	}
+	if _, err := NewSessionItem(func() {}); err == nil {
+		t.Error("unencodable value: expected error, got nil")
+	}
+}
@@ -0,0 +1,104 @@
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Encoding is an HTTP Content-Encoding that an envelope can be compressed
+// with. The zero value means no compression.
+type Encoding string
+
+const (
+	EncodingNone    Encoding = ""
+	EncodingGzip    Encoding = "gzip"
+	EncodingDeflate Encoding = "deflate"
+	EncodingZstd    Encoding = "zstd"
+	EncodingBrotli  Encoding = "br"
+)
+
+var Encodings = []Encoding{EncodingNone, EncodingGzip, EncodingDeflate, EncodingZstd, EncodingBrotli}
+
+func (e Encoding) String() string {
+	if e == EncodingNone {
+		return "none"
+	}
+	return string(e)
+}
+
+// Decompress detects the encoding of r by its magic bytes and returns a
+// reader for the decompressed data. Brotli has no magic bytes, so it is
+// assumed when the data does not look like an envelope but decodes as one.
+func Decompress(r io.Reader) (io.Reader, Encoding, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, EncodingGzip, fmt.Errorf("decompressing gzip: %w", err)
+		}
+		return zr, EncodingGzip, nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		// A single decoder decodes synchronously without leaking goroutines
+		zr, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, EncodingZstd, fmt.Errorf("decompressing zstd: %w", err)
+		}
+		return zr.IOReadCloser(), EncodingZstd, nil
+	case isZlibHeader(magic):
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, EncodingDeflate, fmt.Errorf("decompressing deflate: %w", err)
+		}
+		return zr, EncodingDeflate, nil
+	case len(magic) > 0 && magic[0] != '{' && isBrotli(br):
+		return brotli.NewReader(br), EncodingBrotli, nil
+	}
+	return br, EncodingNone, nil
+}
+
+// Compress returns a writer that compresses to w. The writer must be closed
+// to flush the compressed data.
+func Compress(w io.Writer, enc Encoding) (io.WriteCloser, error) {
+	switch enc {
+	case EncodingNone:
+		return nopCloser{w}, nil
+	case EncodingGzip:
+		return gzip.NewWriter(w), nil
+	case EncodingDeflate:
+		return zlib.NewWriter(w), nil
+	case EncodingZstd:
+		return zstd.NewWriter(w)
+	case EncodingBrotli:
+		return brotli.NewWriter(w), nil
+	}
+	return nil, fmt.Errorf("unsupported encoding: %s", enc)
+}
+
+// isZlibHeader reports whether b starts with a zlib header, which is what
+// HTTP calls deflate.
+func isZlibHeader(b []byte) bool {
+	return len(b) >= 2 && b[0]&0x0f == 8 && b[0]>>4 <= 7 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+func isBrotli(br *bufio.Reader) bool {
+	data, _ := br.Peek(br.Size())
+	first := make([]byte, 1)
+	_, err := io.ReadFull(brotli.NewReader(bytes.NewReader(data)), first)
+	return err == nil && first[0] == '{'
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
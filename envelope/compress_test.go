@@ -0,0 +1,101 @@
+package envelope
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "breakpad.envelope"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, enc := range Encodings {
+		t.Run(enc.String(), func(t *testing.T) {
+			var compressed bytes.Buffer
+			cw, err := Compress(&compressed, enc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cw.Write(data)
+			if err := cw.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			env, err := Parse(bytes.NewReader(compressed.Bytes()))
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if env.Encoding != enc {
+				t.Errorf("encoding = %s, want %s", env.Encoding, enc)
+			}
+			if len(env.Items) != len(orig.Items) {
+				t.Fatalf("item count = %d, want %d", len(env.Items), len(orig.Items))
+			}
+
+			lenient, _, err := ParseLenient(bytes.NewReader(compressed.Bytes()))
+			if err != nil {
+				t.Fatalf("lenient parse error: %v", err)
+			}
+			if lenient.Encoding != enc {
+				t.Errorf("lenient encoding = %s, want %s", lenient.Encoding, enc)
+			}
+
+			// Saved back in the same encoding
+			var buf bytes.Buffer
+			if err := env.SerializeLossless(&buf); err != nil {
+				t.Fatalf("serialize error: %v", err)
+			}
+			dr, got, err := Decompress(&buf)
+			if err != nil {
+				t.Fatalf("decompress error: %v", err)
+			}
+			if got != enc {
+				t.Errorf("saved encoding = %s, want %s", got, enc)
+			}
+			var out bytes.Buffer
+			if _, err := out.ReadFrom(dr); err != nil {
+				t.Fatalf("read error: %v", err)
+			}
+			if !bytes.Equal(out.Bytes(), data) {
+				t.Error("decompressed output differs from input")
+			}
+		})
+	}
+}
+
+func TestSerializeEncoding(t *testing.T) {
+	env, err := Parse(bytes.NewReader([]byte("{}\n{\"type\":\"session\"}\n{}\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Encoding = EncodingZstd
+	var buf bytes.Buffer
+	if err := env.Serialize(&buf); err != nil {
+		t.Fatalf("serialize error: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte{0x28, 0xb5, 0x2f, 0xfd}) {
+		t.Errorf("output is not zstd: %x", buf.Bytes())
+	}
+
+	env.Encoding = "lzma"
+	if err := env.Serialize(&buf); err == nil {
+		t.Error("unsupported encoding: expected error, got nil")
+	}
+}
+
+func TestDecompressCorrupt(t *testing.T) {
+	if _, err := Parse(bytes.NewReader([]byte{0x1f, 0x8b, 0x00})); err == nil {
+		t.Error("corrupt gzip: expected error, got nil")
+	}
+	if _, _, err := ParseLenient(bytes.NewReader([]byte{0x1f, 0x8b, 0x00})); err == nil {
+		t.Error("corrupt gzip lenient: expected error, got nil")
+	}
+}
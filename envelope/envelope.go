@@ -1,20 +1,26 @@
 package envelope
 
 import (
-	"bufio"
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
-	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
-	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"github.com/getsentry/slope/internal/jsonfields"
 )
 
 type Envelope struct {
 	Header json.RawMessage
 	Items  []Item
+
+	// Encoding is the compression the envelope was parsed with and is
+	// serialized with.
+	Encoding Encoding
+
+	raw *rawEnvelope
 }
 
 type Item struct {
@@ -22,88 +28,38 @@ type Item struct {
 	Payload  []byte
 	Type     string
 	Filename string
+
+	raw *rawItem
 }
 
+// Parse reads a whole envelope into memory. Unlike Reader, it accepts a
+// payload of declared length that is directly followed by the next item
+// header, without a newline in between.
 func Parse(r io.Reader) (*Envelope, error) {
-	data, err := io.ReadAll(r)
+	er, err := newReader(r, func(err *ParseError) bool { return err.Kind == KindMissingNewline })
 	if err != nil {
-		return nil, fmt.Errorf("reading envelope: %w", err)
-	}
-
-	buf := bytes.NewBuffer(data)
-	env := &Envelope{}
-
-	// First line: envelope header
-	line, err := buf.ReadBytes('\n')
-	if err != nil && err != io.EOF {
-		return nil, fmt.Errorf("reading envelope header: %w", err)
-	}
-	line = bytes.TrimRight(line, "\n")
-	if len(line) == 0 {
-		return nil, fmt.Errorf("empty envelope header")
-	}
-	if !json.Valid(line) {
-		return nil, fmt.Errorf("invalid JSON in envelope header")
+		return nil, err
 	}
-	env.Header = json.RawMessage(line)
-
-	// Parse items
-	for buf.Len() > 0 {
-		// Read item header line
-		headerLine, err := buf.ReadBytes('\n')
-		if err != nil && err != io.EOF {
-			return nil, fmt.Errorf("reading item header: %w", err)
-		}
-		headerLine = bytes.TrimRight(headerLine, "\n")
-		if len(headerLine) == 0 {
-			continue // skip empty lines (e.g., trailing newline)
-		}
-		if !json.Valid(headerLine) {
-			return nil, fmt.Errorf("invalid JSON in item header: %s", headerLine)
-		}
+	return readEnvelope(er)
+}
 
-		item := Item{
-			Header: json.RawMessage(headerLine),
-		}
+// readEnvelope reads the items of an envelope into memory.
+func readEnvelope(er *Reader) (*Envelope, error) {
+	env := &Envelope{Header: er.Header(), Encoding: er.Encoding(), raw: er.raw}
 
-		// Extract type, length, filename from header
-		var hdr struct {
-			Type     string `json:"type"`
-			Length   *int   `json:"length"`
-			Filename string `json:"filename"`
+	for {
+		item, payload, err := er.Next()
+		if err == io.EOF {
+			break
 		}
-		if err := json.Unmarshal(headerLine, &hdr); err != nil {
-			return nil, fmt.Errorf("parsing item header: %w", err)
+		if err != nil {
+			return nil, err
 		}
-		item.Type = hdr.Type
-		item.Filename = hdr.Filename
-
-		// Read payload
-		if hdr.Length != nil {
-			length := *hdr.Length
-			if buf.Len() < length {
-				return nil, fmt.Errorf("payload truncated: expected %d bytes, got %d", length, buf.Len())
-			}
-			item.Payload = make([]byte, length)
-			if _, err := io.ReadFull(buf, item.Payload); err != nil {
-				return nil, fmt.Errorf("reading payload: %w", err)
-			}
-			// Consume trailing newline
-			if buf.Len() > 0 {
-				b, _ := buf.ReadByte()
-				if b != '\n' {
-					buf.UnreadByte()
-				}
-			}
-		} else {
-			// Read until newline
-			payload, err := buf.ReadBytes('\n')
-			if err != nil && err != io.EOF {
-				return nil, fmt.Errorf("reading payload: %w", err)
-			}
-			item.Payload = bytes.TrimRight(payload, "\n")
+		item.Payload, err = io.ReadAll(payload)
+		if err != nil {
+			return nil, err
 		}
-
+		item.raw.sum = sha256.Sum256(item.Payload)
 		env.Items = append(env.Items, item)
 	}
 
@@ -111,34 +67,23 @@ func Parse(r io.Reader) (*Envelope, error) {
 }
 
 func (env *Envelope) Serialize(w io.Writer) error {
-	bw := bufio.NewWriter(w)
-
-	// Write envelope header (compact)
-	compact, err := compactJSON(env.Header)
+	cw, err := Compress(w, env.Encoding)
 	if err != nil {
-		return fmt.Errorf("compacting envelope header: %w", err)
+		return err
+	}
+	ew, err := NewWriter(cw, env.Header)
+	if err != nil {
+		return err
 	}
-	bw.Write(compact)
-	bw.WriteByte('\n')
-
-	// Write items
 	for _, item := range env.Items {
-		// Update length in header
-		header, err := UpdateLength(item.Header, len(item.Payload))
-		if err != nil {
-			return fmt.Errorf("updating item header: %w", err)
-		}
-		compact, err := compactJSON(header)
-		if err != nil {
-			return fmt.Errorf("compacting item header: %w", err)
+		if err := ew.WriteItem(item.Header, bytes.NewReader(item.Payload), int64(len(item.Payload))); err != nil {
+			return err
 		}
-		bw.Write(compact)
-		bw.WriteByte('\n')
-		bw.Write(item.Payload)
-		bw.WriteByte('\n')
 	}
-
-	return bw.Flush()
+	if err := ew.Flush(); err != nil {
+		return err
+	}
+	return cw.Close()
 }
 
 func IsBinary(data []byte) bool {
@@ -211,10 +156,11 @@ func compactJSON(data json.RawMessage) ([]byte, error) {
 }
 
 func UpdateLength(header json.RawMessage, length int) (json.RawMessage, error) {
-	om := orderedmap.New[string, any]()
-	if err := json.Unmarshal(header, om); err != nil {
+	// Keep values raw so that number literals and nested key order survive.
+	f, err := jsonfields.Decode(header)
+	if err != nil {
 		return nil, err
 	}
-	om.Set("length", length)
-	return json.Marshal(om)
+	f.Set("length", json.RawMessage(strconv.Itoa(length)))
+	return jsonfields.MarshalObject(f)
 }
@@ -348,6 +348,56 @@ func TestOneLineJSONInvalid(t *testing.T) {
 	}
 }
 
+func TestUpdateLengthPreservesValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{
+			"64-bit integer",
+			`{"type":"event","id":18446744073709551615,"length":1}`,
+			`{"type":"event","id":18446744073709551615,"length":42}`,
+		},
+		{
+			"max int64",
+			`{"type":"event","id":9223372036854775807}`,
+			`{"type":"event","id":9223372036854775807,"length":42}`,
+		},
+		{
+			"high-precision float",
+			`{"type":"event","sample_rand":0.29763830177158721234567,"length":1}`,
+			`{"type":"event","sample_rand":0.29763830177158721234567,"length":42}`,
+		},
+		{
+			"exponent",
+			`{"type":"event","x":1e400,"y":1.50E-3}`,
+			`{"type":"event","x":1e400,"y":1.50E-3,"length":42}`,
+		},
+		{
+			"nested order",
+			`{"type":"event","meta":{"z":1,"a":{"y":12345678901234567890,"b":2}}}`,
+			`{"type":"event","meta":{"z":1,"a":{"y":12345678901234567890,"b":2}},"length":42}`,
+		},
+		{
+			"HTML characters",
+			`{"type":"attachment","filename":"a&b<c>.txt","length":1}`,
+			`{"type":"attachment","filename":"a&b<c>.txt","length":42}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UpdateLength(json.RawMessage(tt.header), 42)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestUpdateLengthInvalid(t *testing.T) {
 	_, err := UpdateLength(json.RawMessage("not json"), 42)
 	if err == nil {
@@ -0,0 +1,35 @@
+package envelope
+
+import "fmt"
+
+// ErrorKind classifies a ParseError.
+type ErrorKind string
+
+const (
+	KindReadError        ErrorKind = "read_error"
+	KindEmptyHeader      ErrorKind = "empty_header"
+	KindInvalidHeader    ErrorKind = "invalid_header"
+	KindInvalidLength    ErrorKind = "invalid_length"
+	KindTruncatedPayload ErrorKind = "truncated_payload"
+	KindMissingNewline   ErrorKind = "missing_newline"
+)
+
+// ParseError describes where and why an envelope failed to parse. Offset and
+// Line locate the start of the offending header line or payload.
+type ParseError struct {
+	Kind   ErrorKind
+	Offset int64 // byte offset, starting at 0
+	Line   int   // line number, starting at 1
+	Item   int   // item index, or -1 for the envelope header
+	Err    error // underlying error, if any
+
+	msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s (line %d, offset %d)", e.msg, e.Line, e.Offset)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
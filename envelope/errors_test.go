@@ -0,0 +1,99 @@
+package envelope
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseErrorLocation(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		kind   ErrorKind
+		offset int64
+		line   int
+		item   int
+	}{
+		{"empty input", "", KindEmptyHeader, 0, 1, -1},
+		{"invalid envelope header", "not json\n", KindInvalidHeader, 0, 1, -1},
+		{
+			"invalid item header",
+			"{}\n{\"type\":\"session\"}\n{}\n\nnot json\nhello\n",
+			KindInvalidHeader, 26, 5, 1,
+		},
+		{
+			"invalid length type",
+			"{}\n{\"type\":\"event\",\"length\":\"2\"}\n{}\n",
+			KindInvalidHeader, 3, 2, 0,
+		},
+		{
+			"negative length",
+			"{}\n{\"type\":\"event\",\"length\":-1}\n",
+			KindInvalidLength, 3, 2, 0,
+		},
+		{
+			"truncated payload",
+			"{}\n{\"type\":\"event\",\"length\":2}\n{}\n{\"type\":\"event\",\"length\":100}\nhello\n",
+			KindTruncatedPayload, 64, 5, 1,
+		},
+		{"read error", "", KindReadError, 0, 1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.kind == KindReadError {
+				_, err = Parse(errReader{})
+			} else {
+				_, err = Parse(strings.NewReader(tt.input))
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("err = %v, want *ParseError", err)
+			}
+			if perr.Kind != tt.kind {
+				t.Errorf("kind = %s, want %s", perr.Kind, tt.kind)
+			}
+			if perr.Offset != tt.offset {
+				t.Errorf("offset = %d, want %d", perr.Offset, tt.offset)
+			}
+			if perr.Line != tt.line {
+				t.Errorf("line = %d, want %d", perr.Line, tt.line)
+			}
+			if perr.Item != tt.item {
+				t.Errorf("item = %d, want %d", perr.Item, tt.item)
+			}
+		})
+	}
+}
+
+func TestParseErrorUnwrap(t *testing.T) {
+	_, err := Parse(errReader{})
+	if err == nil || !strings.Contains(err.Error(), "read error") {
+		t.Fatalf("err = %v, want read error", err)
+	}
+	if errors.Unwrap(err) == nil {
+		t.Error("expected wrapped read error")
+	}
+	if !strings.Contains(err.Error(), "line 1, offset 0") {
+		t.Errorf("err = %q, want location", err)
+	}
+}
+
+func TestReaderMissingNewline(t *testing.T) {
+	r, err := NewReader(strings.NewReader("{}\n{\"type\":\"event\",\"length\":2}\n{}x\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := r.Next(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _, err = r.Next()
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("err = %v, want *ParseError", err)
+	}
+	if perr.Kind != KindMissingNewline || perr.Offset != 33 || perr.Line != 3 || perr.Item != 0 {
+		t.Errorf("kind/offset/line/item = %s/%d/%d/%d, want %s/33/3/0", perr.Kind, perr.Offset, perr.Line, perr.Item, KindMissingNewline)
+	}
+}
@@ -0,0 +1,72 @@
+package event
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/getsentry/slope/internal/jsonfields"
+)
+
+// ClientReport is the payload of a client_report item, in which SDKs report
+// the events they dropped.
+type ClientReport struct {
+	Timestamp       Timestamp        `json:"timestamp"`
+	DiscardedEvents []DiscardedEvent `json:"discarded_events"`
+
+	fields *jsonfields.Fields
+}
+
+type DiscardedEvent struct {
+	Reason   string `json:"reason"`
+	Category string `json:"category"`
+	Quantity int    `json:"quantity"`
+
+	fields *jsonfields.Fields
+}
+
+// ParseClientReport decodes a client_report item payload.
+func ParseClientReport(data []byte) (*ClientReport, error) {
+	r := &ClientReport{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("parsing client report: %w", err)
+	}
+	return r, nil
+}
+
+// SumDiscardedEvents adds up the quantities by reason and category, ordered
+// by quantity, largest first.
+func SumDiscardedEvents(events []DiscardedEvent) []DiscardedEvent {
+	type key struct{ reason, category string }
+	sums := map[key]int{}
+	for _, e := range events {
+		sums[key{e.Reason, e.Category}] += e.Quantity
+	}
+	result := make([]DiscardedEvent, 0, len(sums))
+	for k, quantity := range sums {
+		result = append(result, DiscardedEvent{Reason: k.reason, Category: k.category, Quantity: quantity})
+	}
+	slices.SortFunc(result, func(a, b DiscardedEvent) int {
+		return cmp.Or(
+			cmp.Compare(b.Quantity, a.Quantity),
+			cmp.Compare(a.Reason, b.Reason),
+			cmp.Compare(a.Category, b.Category),
+		)
+	})
+	return result
+}
+
+func (r *ClientReport) UnmarshalJSON(data []byte) (err error) {
+	r.fields, err = jsonfields.DecodeStruct(data, r)
+	return err
+}
+
+func (r ClientReport) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&r, r.fields) }
+
+func (e *DiscardedEvent) UnmarshalJSON(data []byte) (err error) {
+	e.fields, err = jsonfields.DecodeStruct(data, e)
+	return err
+}
+
+func (e DiscardedEvent) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&e, e.fields) }
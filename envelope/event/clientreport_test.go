@@ -0,0 +1,47 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getsentry/slope/internal/jsonfields"
+)
+
+func TestParseClientReport(t *testing.T) {
+	input := `{"timestamp":"2025-10-09T08:05:04Z","discarded_events":[` +
+		`{"reason":"queue_overflow","category":"error","quantity":2},` +
+		`{"reason":"ratelimit_backoff","category":"transaction","quantity":5},` +
+		`{"reason":"queue_overflow","category":"error","quantity":4}],"rate_limited_events":[]}`
+	r, err := ParseClientReport([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !r.Timestamp.Equal(time.Date(2025, 10, 9, 8, 5, 4, 0, time.UTC)) || len(r.DiscardedEvents) != 3 {
+		t.Errorf("report = %+v", r)
+	}
+	data, err := jsonfields.Marshal(r)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if string(data) != input {
+		t.Errorf("got:\n%s\nwant:\n%s", data, input)
+	}
+
+	sums := SumDiscardedEvents(r.DiscardedEvents)
+	want := []DiscardedEvent{
+		{Reason: "queue_overflow", Category: "error", Quantity: 6},
+		{Reason: "ratelimit_backoff", Category: "transaction", Quantity: 5},
+	}
+	if len(sums) != len(want) {
+		t.Fatalf("sums = %+v", sums)
+	}
+	for i := range want {
+		if sums[i].Reason != want[i].Reason || sums[i].Category != want[i].Category || sums[i].Quantity != want[i].Quantity {
+			t.Errorf("sum %d = %+v, want %+v", i, sums[i], want[i])
+		}
+	}
+
+	if _, err := ParseClientReport([]byte(`"report"`)); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,70 @@
+package event
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/slope/internal/jsonfields"
+)
+
+// DebugMeta lists the images loaded into the process, which symbolication
+// matches frames against.
+type DebugMeta struct {
+	Images []DebugImage `json:"images"`
+
+	fields *jsonfields.Fields
+}
+
+// DebugImage is a loaded module, or a ProGuard mapping or source map
+// bundle, depending on its type.
+type DebugImage struct {
+	Type        string `json:"type"`
+	CodeFile    string `json:"code_file"`
+	CodeID      string `json:"code_id"`
+	DebugFile   string `json:"debug_file"`
+	DebugID     string `json:"debug_id"`
+	Arch        string `json:"arch"`
+	ImageAddr   string `json:"image_addr"`
+	ImageSize   uint64 `json:"image_size"`
+	ImageVMAddr string `json:"image_vmaddr"`
+	UUID        string `json:"uuid"`
+
+	fields *jsonfields.Fields
+}
+
+// ParseAddr parses an address as used in frames and debug images, which
+// is a hex string with a 0x prefix, or a decimal string.
+func ParseAddr(s string) (uint64, error) {
+	var n uint64
+	var err error
+	if hex, ok := strings.CutPrefix(strings.ToLower(s), "0x"); ok {
+		n, err = strconv.ParseUint(hex, 16, 64)
+	} else {
+		n, err = strconv.ParseUint(s, 10, 64)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid address %q", s)
+	}
+	return n, nil
+}
+
+// Contains reports whether addr falls into the image.
+func (img *DebugImage) Contains(addr uint64) bool {
+	base, err := ParseAddr(img.ImageAddr)
+	return err == nil && addr >= base && addr-base < img.ImageSize
+}
+
+func (m *DebugMeta) UnmarshalJSON(data []byte) (err error) {
+	m.fields, err = jsonfields.DecodeStruct(data, m)
+	return err
+}
+
+func (m DebugMeta) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&m, m.fields) }
+
+func (img *DebugImage) UnmarshalJSON(data []byte) (err error) {
+	img.fields, err = jsonfields.DecodeStruct(data, img)
+	return err
+}
+
+func (img DebugImage) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&img, img.fields) }
@@ -0,0 +1,52 @@
+package event
+
+import (
+	"testing"
+)
+
+func TestParseDebugMeta(t *testing.T) {
+	ev, err := Parse([]byte(`{"debug_meta":{"images":[{"type":"elf","code_file":"/usr/bin/app",` +
+		`"code_id":"89a79abce0b8c4c8e628e26bc0b8e10f38a90b1a","debug_id":"bc9aa789-b8e0-c8c4-e628-e26bc0b8e10f",` +
+		`"image_addr":"0x10000","image_size":8192,"extra":true}]}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ev.DebugMeta == nil || len(ev.DebugMeta.Images) != 1 {
+		t.Fatalf("debug_meta = %+v", ev.DebugMeta)
+	}
+	img := ev.DebugMeta.Images[0]
+	if img.Type != "elf" || img.CodeFile != "/usr/bin/app" || img.ImageAddr != "0x10000" || img.ImageSize != 8192 {
+		t.Errorf("image = %+v", img)
+	}
+	for addr, want := range map[uint64]bool{0xffff: false, 0x10000: true, 0x11fff: true, 0x12000: false} {
+		if got := img.Contains(addr); got != want {
+			t.Errorf("Contains(0x%x) = %v, want %v", addr, got, want)
+		}
+	}
+
+	img.ImageAddr = "0x20000"
+	ev.DebugMeta.Images[0] = img
+	data, err := ev.MarshalJSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"debug_meta":{"images":[{"type":"elf","code_file":"/usr/bin/app",` +
+		`"code_id":"89a79abce0b8c4c8e628e26bc0b8e10f38a90b1a","debug_id":"bc9aa789-b8e0-c8c4-e628-e26bc0b8e10f",` +
+		`"image_addr":"0x20000","image_size":8192,"extra":true}]}}`
+	if string(data) != want {
+		t.Errorf("got  %s\nwant %s", data, want)
+	}
+}
+
+func TestParseAddr(t *testing.T) {
+	for s, want := range map[string]uint64{"0x1a": 26, "0X1A": 26, "26": 26, "0x0": 0} {
+		if got, err := ParseAddr(s); err != nil || got != want {
+			t.Errorf("ParseAddr(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "0x", "zz", "-1"} {
+		if _, err := ParseAddr(s); err == nil {
+			t.Errorf("ParseAddr(%q) succeeded", s)
+		}
+	}
+}
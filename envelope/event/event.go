@@ -0,0 +1,252 @@
+// Package event decodes Sentry event payloads, and the session payloads
+// that accompany them.
+//
+// The types model the commonly used parts of the protocol. Unknown
+// members are kept and written back unchanged, and so are known members
+// whose value has not changed.
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/getsentry/slope/internal/jsonfields"
+)
+
+type Event struct {
+	EventID        string                    `json:"event_id"`
+	Type           string                    `json:"type"`
+	Timestamp      Timestamp                 `json:"timestamp"`
+	StartTimestamp Timestamp                 `json:"start_timestamp"`
+	Platform       string                    `json:"platform"`
+	Level          string                    `json:"level"`
+	Logger         string                    `json:"logger"`
+	Transaction    string                    `json:"transaction"`
+	ServerName     string                    `json:"server_name"`
+	Release        string                    `json:"release"`
+	Dist           string                    `json:"dist"`
+	Environment    string                    `json:"environment"`
+	Message        *Message                  `json:"message"`
+	Logentry       *Message                  `json:"logentry"`
+	Fingerprint    []string                  `json:"fingerprint"`
+	Tags           Tags                      `json:"tags"`
+	Extra          map[string]any            `json:"extra"`
+	User           *User                     `json:"user"`
+	Contexts       map[string]map[string]any `json:"contexts"`
+	SDK            *SDK                      `json:"sdk"`
+	Exception      Values[Exception]         `json:"exception"`
+	Threads        Values[Thread]            `json:"threads"`
+	Breadcrumbs    Values[Breadcrumb]        `json:"breadcrumbs"`
+	Modules        map[string]string         `json:"modules"`
+	Spans          []Span                    `json:"spans"`
+	DebugMeta      *DebugMeta                `json:"debug_meta"`
+
+	fields *jsonfields.Fields
+}
+
+// Message is a log message, which may also be encoded as a plain string.
+type Message struct {
+	Formatted string `json:"formatted"`
+	Message   string `json:"message"`
+	Params    []any  `json:"params"`
+
+	fields *jsonfields.Fields
+}
+
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module"`
+	ThreadID   ID          `json:"thread_id"`
+	Mechanism  *Mechanism  `json:"mechanism"`
+	Stacktrace *Stacktrace `json:"stacktrace"`
+
+	fields *jsonfields.Fields
+}
+
+type Mechanism struct {
+	Type             string         `json:"type"`
+	Description      string         `json:"description"`
+	HelpLink         string         `json:"help_link"`
+	Handled          *bool          `json:"handled"`
+	Synthetic        bool           `json:"synthetic"`
+	Source           string         `json:"source"`
+	ExceptionID      *int           `json:"exception_id"`
+	ParentID         *int           `json:"parent_id"`
+	IsExceptionGroup bool           `json:"is_exception_group"`
+	Data             map[string]any `json:"data"`
+	Meta             map[string]any `json:"meta"`
+
+	fields *jsonfields.Fields
+}
+
+type Stacktrace struct {
+	Frames    []Frame           `json:"frames"`
+	Registers map[string]string `json:"registers"`
+
+	fields *jsonfields.Fields
+}
+
+// Frame is a stack frame. Frames are ordered from the outermost call to the
+// innermost, so the crashing frame comes last.
+type Frame struct {
+	Function        string         `json:"function"`
+	RawFunction     string         `json:"raw_function"`
+	Symbol          string         `json:"symbol"`
+	Module          string         `json:"module"`
+	Package         string         `json:"package"`
+	Filename        string         `json:"filename"`
+	AbsPath         string         `json:"abs_path"`
+	Lineno          int            `json:"lineno"`
+	Colno           int            `json:"colno"`
+	InApp           *bool          `json:"in_app"`
+	Platform        string         `json:"platform"`
+	InstructionAddr string         `json:"instruction_addr"`
+	SymbolAddr      string         `json:"symbol_addr"`
+	ImageAddr       string         `json:"image_addr"`
+	PreContext      []string       `json:"pre_context"`
+	ContextLine     string         `json:"context_line"`
+	PostContext     []string       `json:"post_context"`
+	Vars            map[string]any `json:"vars"`
+
+	fields *jsonfields.Fields
+}
+
+type Thread struct {
+	ID         ID          `json:"id"`
+	Name       string      `json:"name"`
+	Crashed    bool        `json:"crashed"`
+	Current    bool        `json:"current"`
+	Main       bool        `json:"main"`
+	State      string      `json:"state"`
+	Stacktrace *Stacktrace `json:"stacktrace"`
+
+	fields *jsonfields.Fields
+}
+
+type Breadcrumb struct {
+	Timestamp Timestamp      `json:"timestamp"`
+	Type      string         `json:"type"`
+	Category  string         `json:"category"`
+	Level     string         `json:"level"`
+	Message   string         `json:"message"`
+	Data      map[string]any `json:"data"`
+
+	fields *jsonfields.Fields
+}
+
+type User struct {
+	ID        ID             `json:"id"`
+	Email     string         `json:"email"`
+	Username  string         `json:"username"`
+	Name      string         `json:"name"`
+	IPAddress string         `json:"ip_address"`
+	Geo       map[string]any `json:"geo"`
+	Data      map[string]any `json:"data"`
+
+	fields *jsonfields.Fields
+}
+
+type SDK struct {
+	Name         string    `json:"name"`
+	Version      string    `json:"version"`
+	Integrations []string  `json:"integrations"`
+	Packages     []Package `json:"packages"`
+
+	fields *jsonfields.Fields
+}
+
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Parse decodes an event payload.
+func Parse(data []byte) (*Event, error) {
+	ev := &Event{}
+	if err := json.Unmarshal(data, ev); err != nil {
+		return nil, fmt.Errorf("parsing event: %w", err)
+	}
+	return ev, nil
+}
+
+// Encode encodes the event as a payload. Unlike json.Marshal, it does not
+// escape HTML characters.
+func (e *Event) Encode() ([]byte, error) {
+	return jsonfields.Marshal(e)
+}
+
+func (e *Event) UnmarshalJSON(data []byte) (err error) {
+	e.fields, err = jsonfields.DecodeStruct(data, e)
+	return err
+}
+
+func (e Event) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&e, e.fields) }
+
+func (m *Message) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*m = Message{Formatted: s}
+		return nil
+	}
+	m.fields, err = jsonfields.DecodeStruct(data, m)
+	return err
+}
+
+func (m Message) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&m, m.fields) }
+
+func (e *Exception) UnmarshalJSON(data []byte) (err error) {
+	e.fields, err = jsonfields.DecodeStruct(data, e)
+	return err
+}
+
+func (e Exception) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&e, e.fields) }
+
+func (m *Mechanism) UnmarshalJSON(data []byte) (err error) {
+	m.fields, err = jsonfields.DecodeStruct(data, m)
+	return err
+}
+
+func (m Mechanism) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&m, m.fields) }
+
+func (s *Stacktrace) UnmarshalJSON(data []byte) (err error) {
+	s.fields, err = jsonfields.DecodeStruct(data, s)
+	return err
+}
+
+func (s Stacktrace) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&s, s.fields) }
+
+func (f *Frame) UnmarshalJSON(data []byte) (err error) {
+	f.fields, err = jsonfields.DecodeStruct(data, f)
+	return err
+}
+
+func (f Frame) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&f, f.fields) }
+
+func (t *Thread) UnmarshalJSON(data []byte) (err error) {
+	t.fields, err = jsonfields.DecodeStruct(data, t)
+	return err
+}
+
+func (t Thread) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&t, t.fields) }
+
+func (b *Breadcrumb) UnmarshalJSON(data []byte) (err error) {
+	b.fields, err = jsonfields.DecodeStruct(data, b)
+	return err
+}
+
+func (b Breadcrumb) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&b, b.fields) }
+
+func (u *User) UnmarshalJSON(data []byte) (err error) {
+	u.fields, err = jsonfields.DecodeStruct(data, u)
+	return err
+}
+
+func (u User) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&u, u.fields) }
+
+func (s *SDK) UnmarshalJSON(data []byte) (err error) {
+	s.fields, err = jsonfields.DecodeStruct(data, s)
+	return err
+}
+
+func (s SDK) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&s, s.fields) }
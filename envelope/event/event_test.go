@@ -0,0 +1,179 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+const nativeEvent = `{"event_id":"4bf326c30e574542e355035a23d438df","timestamp":1760000000.5,"platform":"native","level":"fatal",` +
+	`"exception":{"values":[{"type":"SIGSEGV","value":"Segfault","thread_id":259,` +
+	`"mechanism":{"type":"signalhandler","synthetic":true,"handled":false,"meta":{"signal":{"name":"SIGSEGV","number":11}}},` +
+	`"stacktrace":{"frames":[{"function":"main","instruction_addr":"0x1000","in_app":true},` +
+	`{"function":"crash","filename":"crash.c","lineno":42,"instruction_addr":"0x1010","package":"/usr/bin/app","addr_mode":"abs"}],"registers":{"rip":"0x1010"}}}]},` +
+	`"threads":{"values":[{"id":"259","crashed":true,"name":"main"}]},` +
+	`"user":{"id":42,"username":"nobody"},"tags":[["backend","inproc"],["retries",3]],` +
+	`"contexts":{"os":{"name":"Linux","version":"6.14.0"}},` +
+	`"breadcrumbs":[{"timestamp":"2025-10-09T08:05:04.920427Z","message":"trigger_crash","category":"default","level":"debug","data":{"line":111}}],` +
+	`"sdk":{"name":"sentry.native","version":"0.11.2","packages":[{"name":"github:getsentry/sentry-native","version":"0.11.2"}]},` +
+	`"unknown":{"b":1,"a":[1.50,"<x>"]}}`
+
+func TestParse(t *testing.T) {
+	ev, err := Parse([]byte(nativeEvent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ev.Level != "fatal" || ev.Platform != "native" {
+		t.Errorf("level/platform = %q/%q", ev.Level, ev.Platform)
+	}
+	if want := time.Unix(1760000000, 5e8).UTC(); !ev.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", ev.Timestamp, want)
+	}
+
+	if len(ev.Exception) != 1 {
+		t.Fatalf("expected 1 exception, got %d", len(ev.Exception))
+	}
+	exc := ev.Exception[0]
+	if exc.Type != "SIGSEGV" || exc.Value != "Segfault" || exc.ThreadID != "259" {
+		t.Errorf("exception = %q %q thread %q", exc.Type, exc.Value, exc.ThreadID)
+	}
+	if exc.Mechanism == nil || exc.Mechanism.Type != "signalhandler" || !exc.Mechanism.Synthetic ||
+		exc.Mechanism.Handled == nil || *exc.Mechanism.Handled {
+		t.Errorf("mechanism = %+v", exc.Mechanism)
+	}
+	frames := exc.Stacktrace.Frames
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(frames))
+	}
+	if f := frames[1]; f.Function != "crash" || f.Filename != "crash.c" || f.Lineno != 42 || f.InstructionAddr != "0x1010" || f.InApp != nil {
+		t.Errorf("frame = %+v", f)
+	}
+	if f := frames[0]; f.InApp == nil || !*f.InApp {
+		t.Errorf("frame 0 in_app = %v, want true", f.InApp)
+	}
+	if exc.Stacktrace.Registers["rip"] != "0x1010" {
+		t.Errorf("registers = %v", exc.Stacktrace.Registers)
+	}
+
+	if len(ev.Threads) != 1 || ev.Threads[0].ID != "259" || !ev.Threads[0].Crashed {
+		t.Errorf("threads = %+v", ev.Threads)
+	}
+	if ev.User == nil || ev.User.ID != "42" || ev.User.Username != "nobody" {
+		t.Errorf("user = %+v", ev.User)
+	}
+	if ev.Tags["backend"] != "inproc" || ev.Tags["retries"] != "3" {
+		t.Errorf("tags = %v", ev.Tags)
+	}
+	if ev.Contexts["os"]["name"] != "Linux" {
+		t.Errorf("contexts = %v", ev.Contexts)
+	}
+	if len(ev.Breadcrumbs) != 1 {
+		t.Fatalf("expected 1 breadcrumb, got %d", len(ev.Breadcrumbs))
+	}
+	if b := ev.Breadcrumbs[0]; b.Category != "default" || b.Level != "debug" || b.Message != "trigger_crash" || b.Data["line"] != 111.0 {
+		t.Errorf("breadcrumb = %+v", b)
+	}
+	if ev.SDK == nil || ev.SDK.Name != "sentry.native" || len(ev.SDK.Packages) != 1 {
+		t.Errorf("sdk = %+v", ev.SDK)
+	}
+}
+
+func TestEncodeUnchanged(t *testing.T) {
+	ev, err := Parse([]byte(nativeEvent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := ev.Encode()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if string(data) != nativeEvent {
+		t.Errorf("got:\n%s\nwant:\n%s", data, nativeEvent)
+	}
+}
+
+func TestEncodeModified(t *testing.T) {
+	ev, err := Parse([]byte(nativeEvent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	frame := &ev.Exception[0].Stacktrace.Frames[0]
+	frame.Function = "main_<int>"
+	frame.Filename = "main.c"
+	frame.Lineno = 7
+	ev.Level = ""
+	ev.Release = "app@1.0"
+
+	data, err := ev.Encode()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	for _, want := range []string{
+		`{"event_id":"4bf326c30e574542e355035a23d438df","timestamp":1760000000.5,"platform":"native","exception":`,
+		`{"function":"main_<int>","instruction_addr":"0x1000","in_app":true,"filename":"main.c","lineno":7}`,
+		`"package":"/usr/bin/app","addr_mode":"abs"}`,
+		`"tags":[["backend","inproc"],["retries",3]]`,
+		`"unknown":{"b":1,"a":[1.50,"<x>"]},"release":"app@1.0"}`,
+	} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("missing %s in:\n%s", want, data)
+		}
+	}
+	if !json.Valid(data) {
+		t.Errorf("invalid JSON: %s", data)
+	}
+}
+
+func TestParseAlternativeForms(t *testing.T) {
+	ev, err := Parse([]byte(`{"message":"hello","exception":[{"type":"Error"}],"breadcrumbs":{"values":[{"timestamp":1.25}]},"tags":{"a":"b"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ev.Message == nil || ev.Message.Formatted != "hello" {
+		t.Errorf("message = %+v", ev.Message)
+	}
+	if len(ev.Exception) != 1 || ev.Exception[0].Type != "Error" {
+		t.Errorf("exception = %+v", ev.Exception)
+	}
+	if len(ev.Breadcrumbs) != 1 || !ev.Breadcrumbs[0].Timestamp.Equal(time.Unix(1, 25e7)) {
+		t.Errorf("breadcrumbs = %+v", ev.Breadcrumbs)
+	}
+	if ev.Tags["a"] != "b" {
+		t.Errorf("tags = %v", ev.Tags)
+	}
+}
+
+func TestParseMistyped(t *testing.T) {
+	input := `{"level":42,"exception":{"values":[{"type":"E","stacktrace":{"frames":[{"lineno":"12","function":"f"}]}}]}}`
+	ev, err := Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ev.Level != "" {
+		t.Errorf("level = %q, want empty", ev.Level)
+	}
+	frame := &ev.Exception[0].Stacktrace.Frames[0]
+	if frame.Function != "f" || frame.Lineno != 0 {
+		t.Errorf("frame = %+v", frame)
+	}
+	frame.Function = "g"
+
+	data, err := ev.Encode()
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	want := `{"level":42,"exception":{"values":[{"type":"E","stacktrace":{"frames":[{"lineno":"12","function":"g"}]}}]}}`
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range []string{`[]`, `"event"`, `{`} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("%s: expected error, got nil", input)
+		}
+	}
+}
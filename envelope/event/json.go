@@ -0,0 +1,120 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/getsentry/slope/internal/jsonfields"
+)
+
+// Values is a list encoded as {"values":[...]}. A bare array is accepted as
+// well, as sent by some SDKs.
+type Values[T any] []T
+
+func (v *Values[T]) UnmarshalJSON(data []byte) error {
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]T)(v))
+	}
+	var obj struct {
+		Values []T `json:"values"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*v = obj.Values
+	return nil
+}
+
+func (v Values[T]) MarshalJSON() ([]byte, error) {
+	return jsonfields.Marshal(struct {
+		Values []T `json:"values"`
+	}{v})
+}
+
+// Tags accepts both the object form and the list of pairs form. Values that
+// are not strings are kept in their JSON form.
+type Tags map[string]string
+
+func (t *Tags) UnmarshalJSON(data []byte) error {
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		var pairs [][2]json.RawMessage
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			return err
+		}
+		*t = make(Tags, len(pairs))
+		for _, pair := range pairs {
+			(*t)[rawString(pair[0])] = rawString(pair[1])
+		}
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*t = make(Tags, len(obj))
+	for k, v := range obj {
+		(*t)[k] = rawString(v)
+	}
+	return nil
+}
+
+func rawString(data json.RawMessage) string {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return s
+	}
+	return string(data)
+}
+
+// ID is an identifier that may be encoded as a JSON string or number.
+type ID string
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = ID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = ID(n)
+	return nil
+}
+
+// Timestamp is a point in time encoded as an RFC 3339 string or as seconds
+// since the Unix epoch.
+type Timestamp struct {
+	time.Time
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*t = Timestamp{}
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		return t.Time.UnmarshalJSON(data)
+	}
+	secs, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return err
+	}
+	// Float seconds carry about microsecond precision
+	sec := int64(secs)
+	usec := int64(math.Round((secs - float64(sec)) * 1e6))
+	t.Time = time.Unix(sec, usec*1e3).UTC()
+	return nil
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return t.Time.MarshalJSON()
+}
@@ -0,0 +1,125 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/slope/internal/jsonfields"
+)
+
+// Session is a session update, the payload of a session item.
+type Session struct {
+	SID               string       `json:"sid"`
+	DID               ID           `json:"did"`
+	Seq               *int64       `json:"seq"`
+	Init              bool         `json:"init"`
+	Timestamp         Timestamp    `json:"timestamp"`
+	Started           Timestamp    `json:"started"`
+	Duration          *float64     `json:"duration"`
+	Status            string       `json:"status"`
+	Errors            int          `json:"errors"`
+	AbnormalMechanism string       `json:"abnormal_mechanism"`
+	Attrs             SessionAttrs `json:"attrs"`
+
+	fields *jsonfields.Fields
+}
+
+type SessionAttrs struct {
+	Release     string `json:"release"`
+	Environment string `json:"environment"`
+	IPAddress   string `json:"ip_address"`
+	UserAgent   string `json:"user_agent"`
+
+	fields *jsonfields.Fields
+}
+
+// SessionAggregates is the payload of a sessions item, which counts sessions
+// in buckets by start time instead of sending individual updates.
+type SessionAggregates struct {
+	Aggregates []SessionBucket `json:"aggregates"`
+	Attrs      SessionAttrs    `json:"attrs"`
+
+	fields *jsonfields.Fields
+}
+
+type SessionBucket struct {
+	Started  Timestamp `json:"started"`
+	DID      ID        `json:"did"`
+	Exited   int       `json:"exited"`
+	Errored  int       `json:"errored"`
+	Abnormal int       `json:"abnormal"`
+	Crashed  int       `json:"crashed"`
+
+	fields *jsonfields.Fields
+}
+
+// SessionStatuses are the valid session statuses. All but "ok" end the
+// session.
+var SessionStatuses = []string{"ok", "exited", "crashed", "abnormal", "errored"}
+
+// Ended reports whether the update ends the session.
+func (s *Session) Ended() bool {
+	return s.Status != "" && s.Status != "ok"
+}
+
+// Elapsed returns the duration of the session, if known.
+func (s *Session) Elapsed() (time.Duration, bool) {
+	if s.Duration == nil {
+		return 0, false
+	}
+	return time.Duration(*s.Duration * float64(time.Second)), true
+}
+
+// Total returns the number of sessions counted in the bucket.
+func (b SessionBucket) Total() int {
+	return b.Exited + b.Errored + b.Abnormal + b.Crashed
+}
+
+// ParseSession decodes a session item payload.
+func ParseSession(data []byte) (*Session, error) {
+	s := &Session{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing session: %w", err)
+	}
+	return s, nil
+}
+
+// ParseSessionAggregates decodes a sessions item payload.
+func ParseSessionAggregates(data []byte) (*SessionAggregates, error) {
+	a := &SessionAggregates{}
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, fmt.Errorf("parsing sessions: %w", err)
+	}
+	return a, nil
+}
+
+func (s *Session) UnmarshalJSON(data []byte) (err error) {
+	s.fields, err = jsonfields.DecodeStruct(data, s)
+	return err
+}
+
+func (s Session) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&s, s.fields) }
+
+func (a *SessionAttrs) UnmarshalJSON(data []byte) (err error) {
+	a.fields, err = jsonfields.DecodeStruct(data, a)
+	return err
+}
+
+func (a SessionAttrs) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&a, a.fields) }
+
+func (a *SessionAggregates) UnmarshalJSON(data []byte) (err error) {
+	a.fields, err = jsonfields.DecodeStruct(data, a)
+	return err
+}
+
+func (a SessionAggregates) MarshalJSON() ([]byte, error) {
+	return jsonfields.EncodeStruct(&a, a.fields)
+}
+
+func (b *SessionBucket) UnmarshalJSON(data []byte) (err error) {
+	b.fields, err = jsonfields.DecodeStruct(data, b)
+	return err
+}
+
+func (b SessionBucket) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&b, b.fields) }
@@ -0,0 +1,58 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getsentry/slope/internal/jsonfields"
+)
+
+func TestParseSession(t *testing.T) {
+	input := `{"init":true,"sid":"e2b6cc4c-8a71-4a4c-b1d9-5ba1db7a0a53","status":"crashed","did":42,"errors":1,` +
+		`"started":"2025-10-09T08:05:03.849779Z","duration":1.070484,"attrs":{"release":"app@1.2.3","environment":"development"}}`
+	s, err := ParseSession([]byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.SID != "e2b6cc4c-8a71-4a4c-b1d9-5ba1db7a0a53" || s.DID != "42" || !s.Init || s.Status != "crashed" || s.Errors != 1 {
+		t.Errorf("session = %+v", s)
+	}
+	if s.Attrs.Release != "app@1.2.3" || s.Attrs.Environment != "development" {
+		t.Errorf("attrs = %+v", s.Attrs)
+	}
+	if !s.Ended() {
+		t.Error("crashed session should have ended")
+	}
+	if d, ok := s.Elapsed(); !ok || d.Round(time.Millisecond) != 1070*time.Millisecond {
+		t.Errorf("elapsed = %v, %v", d, ok)
+	}
+
+	data, err := jsonfields.Marshal(s)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if string(data) != input {
+		t.Errorf("got:\n%s\nwant:\n%s", data, input)
+	}
+
+	ok := &Session{Status: "ok"}
+	if ok.Ended() {
+		t.Error("ok session should not have ended")
+	}
+	if _, known := ok.Elapsed(); known {
+		t.Error("elapsed should be unknown without duration")
+	}
+}
+
+func TestParseSessionAggregates(t *testing.T) {
+	a, err := ParseSessionAggregates([]byte(`{"aggregates":[{"started":"2025-10-09T08:00:00Z","exited":3,"crashed":1},{"started":"2025-10-09T08:01:00Z","errored":2,"abnormal":1}],"attrs":{"release":"app@1.2.3"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(a.Aggregates) != 2 || a.Aggregates[0].Total() != 4 || a.Aggregates[1].Total() != 3 || a.Attrs.Release != "app@1.2.3" {
+		t.Errorf("aggregates = %+v", a)
+	}
+	if _, err := ParseSessionAggregates([]byte(`[]`)); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,95 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/getsentry/slope/internal/jsonfields"
+)
+
+// Span is a span of a transaction, or a standalone span. Both the
+// transaction format, ending at Timestamp, and the span v2 format, ending at
+// EndTimestamp and described by Name, are decoded.
+type Span struct {
+	TraceID        string         `json:"trace_id"`
+	SpanID         string         `json:"span_id"`
+	ParentSpanID   string         `json:"parent_span_id"`
+	Op             string         `json:"op"`
+	Description    string         `json:"description"`
+	Name           string         `json:"name"`
+	Status         string         `json:"status"`
+	StartTimestamp Timestamp      `json:"start_timestamp"`
+	Timestamp      Timestamp      `json:"timestamp"`
+	EndTimestamp   Timestamp      `json:"end_timestamp"`
+	Origin         string         `json:"origin"`
+	Tags           Tags           `json:"tags"`
+	Data           map[string]any `json:"data"`
+
+	fields *jsonfields.Fields
+}
+
+// End returns the end time of the span in either format.
+func (s Span) End() Timestamp {
+	if s.Timestamp.IsZero() {
+		return s.EndTimestamp
+	}
+	return s.Timestamp
+}
+
+// Label returns the description of the span in either format.
+func (s Span) Label() string {
+	if s.Description == "" {
+		return s.Name
+	}
+	return s.Description
+}
+
+func (s *Span) UnmarshalJSON(data []byte) (err error) {
+	s.fields, err = jsonfields.DecodeStruct(data, s)
+	return err
+}
+
+func (s Span) MarshalJSON() ([]byte, error) { return jsonfields.EncodeStruct(&s, s.fields) }
+
+// ParseSpans decodes the payload of a span item, which holds either a single
+// span or a list of spans under "items".
+func ParseSpans(data []byte) ([]Span, error) {
+	var container struct {
+		Items []Span `json:"items"`
+	}
+	if err := json.Unmarshal(data, &container); err != nil {
+		return nil, fmt.Errorf("parsing spans: %w", err)
+	}
+	if container.Items != nil {
+		return container.Items, nil
+	}
+	var span Span
+	if err := json.Unmarshal(data, &span); err != nil {
+		return nil, fmt.Errorf("parsing spans: %w", err)
+	}
+	return []Span{span}, nil
+}
+
+// RootSpan returns the span of a transaction itself, described by its trace
+// context, or nil if the event is not a transaction.
+func (e *Event) RootSpan() *Span {
+	if e.Type != "transaction" && e.StartTimestamp.IsZero() {
+		return nil
+	}
+	trace := e.Contexts["trace"]
+	str := func(key string) string {
+		s, _ := trace[key].(string)
+		return s
+	}
+	return &Span{
+		TraceID:        str("trace_id"),
+		SpanID:         str("span_id"),
+		ParentSpanID:   str("parent_span_id"),
+		Op:             str("op"),
+		Description:    e.Transaction,
+		Status:         str("status"),
+		StartTimestamp: e.StartTimestamp,
+		Timestamp:      e.Timestamp,
+		Origin:         str("origin"),
+	}
+}
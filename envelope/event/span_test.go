@@ -0,0 +1,54 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRootSpan(t *testing.T) {
+	ev, err := Parse([]byte(`{"type":"transaction","transaction":"GET /api","start_timestamp":1760000000,"timestamp":1760000001.25,` +
+		`"contexts":{"trace":{"trace_id":"ba5b92f3d5414dff096ddce6d7e66fa1","span_id":"80a068ef54a34283","op":"http.server","status":"ok"}},` +
+		`"spans":[{"span_id":"a1","parent_span_id":"80a068ef54a34283","op":"db","description":"SELECT 1","start_timestamp":1760000000.5,"timestamp":1760000001}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := ev.RootSpan()
+	if root == nil {
+		t.Fatal("root span is nil")
+	}
+	if root.SpanID != "80a068ef54a34283" || root.Op != "http.server" || root.Label() != "GET /api" || root.Status != "ok" {
+		t.Errorf("root = %+v", root)
+	}
+	if d := root.End().Sub(root.StartTimestamp.Time); d != 1250*time.Millisecond {
+		t.Errorf("root duration = %v, want 1.25s", d)
+	}
+	if len(ev.Spans) != 1 || ev.Spans[0].ParentSpanID != root.SpanID || ev.Spans[0].Label() != "SELECT 1" {
+		t.Errorf("spans = %+v", ev.Spans)
+	}
+
+	if (&Event{Level: "error"}).RootSpan() != nil {
+		t.Error("error event should have no root span")
+	}
+}
+
+func TestParseSpans(t *testing.T) {
+	spans, err := ParseSpans([]byte(`{"span_id":"a1","op":"db","start_timestamp":1,"timestamp":2,"is_segment":true}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(spans) != 1 || spans[0].SpanID != "a1" || spans[0].End().Unix() != 2 {
+		t.Errorf("spans = %+v", spans)
+	}
+
+	spans, err = ParseSpans([]byte(`{"items":[{"span_id":"b1","name":"GET /","start_timestamp":1,"end_timestamp":3},{"span_id":"b2","parent_span_id":"b1"}]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(spans) != 2 || spans[0].Label() != "GET /" || spans[0].End().Unix() != 3 || spans[1].ParentSpanID != "b1" {
+		t.Errorf("spans = %+v", spans)
+	}
+
+	if _, err := ParseSpans([]byte(`[]`)); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
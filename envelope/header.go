@@ -0,0 +1,291 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/getsentry/slope/internal/jsonfields"
+)
+
+// Header is the decoded envelope header. Fields not modelled here are kept
+// and written back unchanged.
+type Header struct {
+	EventID string
+	DSN     string
+	SentAt  time.Time
+	SDK     *SDK
+	Trace   *TraceContext
+
+	fields *jsonfields.Fields
+}
+
+type SDK struct {
+	Name         string
+	Version      string
+	Integrations []string
+	Packages     []SDKPackage
+
+	fields *jsonfields.Fields
+}
+
+type SDKPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// TraceContext is the dynamic sampling context carried in the "trace" field.
+// SampleRate, SampleRand and Sampled accept both JSON numbers/booleans and
+// their string forms, and keep the original form when written back.
+type TraceContext struct {
+	TraceID     string
+	PublicKey   string
+	OrgID       string
+	Release     string
+	Environment string
+	Transaction string
+	ReplayID    string
+	SampleRate  *float64
+	SampleRand  *float64
+	Sampled     *bool
+
+	fields *jsonfields.Fields
+}
+
+// ParseHeader decodes the envelope header.
+func (env *Envelope) ParseHeader() (*Header, error) {
+	h := &Header{}
+	if err := json.Unmarshal(env.Header, h); err != nil {
+		return nil, fmt.Errorf("parsing envelope header: %w", err)
+	}
+	return h, nil
+}
+
+// SetHeader encodes h as the envelope header. The header is left untouched
+// if h carries no changes.
+func (env *Envelope) SetHeader(h *Header) error {
+	data, err := jsonfields.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("encoding envelope header: %w", err)
+	}
+	if compact, err := compactJSON(env.Header); err == nil && bytes.Equal(compact, data) {
+		return nil
+	}
+	env.Header = json.RawMessage(data)
+	return nil
+}
+
+func (h *Header) UnmarshalJSON(data []byte) error {
+	f, err := jsonfields.Decode(data)
+	if err != nil {
+		return err
+	}
+	*h = Header{fields: f}
+	for key, v := range map[string]any{
+		"event_id": &h.EventID,
+		"dsn":      &h.DSN,
+		"sent_at":  &h.SentAt,
+		"sdk":      &h.SDK,
+		"trace":    &h.Trace,
+	} {
+		if err := jsonfields.Get(f, key, v); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (h Header) MarshalJSON() ([]byte, error) {
+	f := jsonfields.Copy(h.fields)
+	if err := jsonfields.Set(f, "event_id", h.EventID, h.EventID == ""); err != nil {
+		return nil, err
+	}
+	if err := jsonfields.Set(f, "dsn", h.DSN, h.DSN == ""); err != nil {
+		return nil, err
+	}
+	if err := jsonfields.Set(f, "sent_at", h.SentAt, h.SentAt.IsZero()); err != nil {
+		return nil, err
+	}
+	if err := jsonfields.Set(f, "sdk", h.SDK, h.SDK == nil); err != nil {
+		return nil, err
+	}
+	if err := jsonfields.Set(f, "trace", h.Trace, h.Trace == nil); err != nil {
+		return nil, err
+	}
+	return jsonfields.MarshalObject(f)
+}
+
+func (s *SDK) UnmarshalJSON(data []byte) error {
+	f, err := jsonfields.Decode(data)
+	if err != nil {
+		return err
+	}
+	*s = SDK{fields: f}
+	for key, v := range map[string]any{
+		"name":         &s.Name,
+		"version":      &s.Version,
+		"integrations": &s.Integrations,
+		"packages":     &s.Packages,
+	} {
+		if err := jsonfields.Get(f, key, v); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (s SDK) MarshalJSON() ([]byte, error) {
+	f := jsonfields.Copy(s.fields)
+	if err := jsonfields.Set(f, "name", s.Name, s.Name == ""); err != nil {
+		return nil, err
+	}
+	if err := jsonfields.Set(f, "version", s.Version, s.Version == ""); err != nil {
+		return nil, err
+	}
+	if err := jsonfields.Set(f, "integrations", s.Integrations, s.Integrations == nil); err != nil {
+		return nil, err
+	}
+	if err := jsonfields.Set(f, "packages", s.Packages, s.Packages == nil); err != nil {
+		return nil, err
+	}
+	return jsonfields.MarshalObject(f)
+}
+
+func (t *TraceContext) UnmarshalJSON(data []byte) error {
+	f, err := jsonfields.Decode(data)
+	if err != nil {
+		return err
+	}
+	*t = TraceContext{fields: f}
+	for key, v := range map[string]*string{
+		"trace_id":    &t.TraceID,
+		"public_key":  &t.PublicKey,
+		"org_id":      &t.OrgID,
+		"release":     &t.Release,
+		"environment": &t.Environment,
+		"transaction": &t.Transaction,
+		"replay_id":   &t.ReplayID,
+	} {
+		if err := jsonfields.Get(f, key, v); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	if t.SampleRate, err = decodeFloat(f, "sample_rate"); err != nil {
+		return err
+	}
+	if t.SampleRand, err = decodeFloat(f, "sample_rand"); err != nil {
+		return err
+	}
+	if t.Sampled, err = decodeBool(f, "sampled"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t TraceContext) MarshalJSON() ([]byte, error) {
+	f := jsonfields.Copy(t.fields)
+	for _, field := range []struct {
+		key   string
+		value string
+	}{
+		{"trace_id", t.TraceID},
+		{"public_key", t.PublicKey},
+		{"org_id", t.OrgID},
+		{"release", t.Release},
+		{"environment", t.Environment},
+		{"transaction", t.Transaction},
+		{"replay_id", t.ReplayID},
+	} {
+		// Keep empty strings that were present, such as "org_id":""
+		_, present := f.Get(field.key)
+		if err := jsonfields.Set(f, field.key, field.value, field.value == "" && !present); err != nil {
+			return nil, err
+		}
+	}
+	if err := encodeFloat(f, "sample_rate", t.SampleRate); err != nil {
+		return nil, err
+	}
+	if err := encodeFloat(f, "sample_rand", t.SampleRand); err != nil {
+		return nil, err
+	}
+	if err := encodeBool(f, "sampled", t.Sampled); err != nil {
+		return nil, err
+	}
+	return jsonfields.MarshalObject(f)
+}
+
+func decodeFloat(f *jsonfields.Fields, key string) (*float64, error) {
+	data, ok := f.Get(key)
+	if !ok {
+		return nil, nil
+	}
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		var s string
+		if json.Unmarshal(data, &s) != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if s == "" {
+			return nil, nil
+		}
+		if v, err = strconv.ParseFloat(s, 64); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return &v, nil
+}
+
+func encodeFloat(f *jsonfields.Fields, key string, v *float64) error {
+	if v == nil {
+		if old, ok := f.Get(key); ok && string(old) == `""` {
+			return nil
+		}
+		return jsonfields.Set(f, key, nil, true)
+	}
+	var value any = *v
+	if old, _ := f.Get(key); isQuoted(old) {
+		value = strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+	return jsonfields.Set(f, key, value, false)
+}
+
+func decodeBool(f *jsonfields.Fields, key string) (*bool, error) {
+	data, ok := f.Get(key)
+	if !ok {
+		return nil, nil
+	}
+	var v bool
+	if err := json.Unmarshal(data, &v); err != nil {
+		var s string
+		if json.Unmarshal(data, &s) != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if s == "" {
+			return nil, nil
+		}
+		if v, err = strconv.ParseBool(s); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return &v, nil
+}
+
+func encodeBool(f *jsonfields.Fields, key string, v *bool) error {
+	if v == nil {
+		if old, ok := f.Get(key); ok && string(old) == `""` {
+			return nil
+		}
+		return jsonfields.Set(f, key, nil, true)
+	}
+	var value any = *v
+	if old, _ := f.Get(key); isQuoted(old) {
+		value = strconv.FormatBool(*v)
+	}
+	return jsonfields.Set(f, key, value, false)
+}
+
+func isQuoted(data json.RawMessage) bool {
+	return len(data) > 0 && data[0] == '"'
+}
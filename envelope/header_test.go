@@ -0,0 +1,133 @@
+package envelope
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseHeader(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "inproc.envelope"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	env, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := env.ParseHeader()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.EventID != "4bf326c3-0e57-4542-e355-035a23d438df" {
+		t.Errorf("event_id = %q", h.EventID)
+	}
+	if h.DSN != "http://b24ad083e8381f56eba37ed0ca79e4f0@127.0.0.1:8000/3" {
+		t.Errorf("dsn = %q", h.DSN)
+	}
+	if h.Trace == nil {
+		t.Fatal("trace = nil")
+	}
+	if h.Trace.TraceID != "ba5b92f3d5414dff096ddce6d7e66fa1" {
+		t.Errorf("trace_id = %q", h.Trace.TraceID)
+	}
+	if h.Trace.PublicKey != "b24ad083e8381f56eba37ed0ca79e4f0" {
+		t.Errorf("public_key = %q", h.Trace.PublicKey)
+	}
+	if h.Trace.SampleRate == nil || *h.Trace.SampleRate != 0 {
+		t.Errorf("sample_rate = %v, want 0", h.Trace.SampleRate)
+	}
+	if h.Trace.SampleRand == nil || *h.Trace.SampleRand != 0.8030819270468339 {
+		t.Errorf("sample_rand = %v, want 0.8030819270468339", h.Trace.SampleRand)
+	}
+	if h.Trace.Sampled == nil || *h.Trace.Sampled {
+		t.Errorf("sampled = %v, want false", h.Trace.Sampled)
+	}
+
+	// Unchanged header is left as is
+	orig := string(env.Header)
+	if err := env.SetHeader(h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(env.Header) != orig {
+		t.Errorf("header changed:\n%s\nwant:\n%s", env.Header, orig)
+	}
+}
+
+func TestSetHeader(t *testing.T) {
+	env := &Envelope{Header: json.RawMessage(`{"custom":{"z":1,"a":"x&y"},"event_id":"abc","sdk":{"name":"sentry.native","version":"0.7.0","extra":true},` +
+		`"trace":{"trace_id":"t1","org_id":"","sample_rate":"0.5","sample_rand":0.29763830177158721234,"sampled":"false","transaction":"<a&b>"}}`)}
+
+	h, err := env.ParseHeader()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.SDK == nil || h.SDK.Name != "sentry.native" || h.SDK.Version != "0.7.0" {
+		t.Errorf("sdk = %+v", h.SDK)
+	}
+	if h.Trace.SampleRate == nil || *h.Trace.SampleRate != 0.5 {
+		t.Errorf("sample_rate = %v, want 0.5", h.Trace.SampleRate)
+	}
+
+	sampled := true
+	h.EventID = "def"
+	h.SentAt = time.Date(2025, 10, 15, 12, 5, 52, 0, time.UTC)
+	h.Trace.Sampled = &sampled
+	h.SDK.Version = "0.8.0"
+	if err := env.SetHeader(h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"custom":{"z":1,"a":"x&y"},"event_id":"def","sdk":{"name":"sentry.native","version":"0.8.0","extra":true},` +
+		`"trace":{"trace_id":"t1","org_id":"","sample_rate":"0.5","sample_rand":0.29763830177158721234,"sampled":"true","transaction":"<a&b>"},` +
+		`"sent_at":"2025-10-15T12:05:52Z"}`
+	if string(env.Header) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", env.Header, want)
+	}
+
+	h.SDK = nil
+	h.Trace = nil
+	if err := env.SetHeader(h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = `{"custom":{"z":1,"a":"x&y"},"event_id":"def","sent_at":"2025-10-15T12:05:52Z"}`
+	if string(env.Header) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", env.Header, want)
+	}
+}
+
+func TestSetHeaderNew(t *testing.T) {
+	rate := 1.0
+	env := &Envelope{}
+	h := &Header{
+		EventID: "abc",
+		Trace:   &TraceContext{TraceID: "t1", PublicKey: "pk", SampleRate: &rate},
+	}
+	if err := env.SetHeader(h); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"event_id":"abc","trace":{"trace_id":"t1","public_key":"pk","sample_rate":1}}`
+	if string(env.Header) != want {
+		t.Errorf("got %s, want %s", env.Header, want)
+	}
+}
+
+func TestParseHeaderInvalid(t *testing.T) {
+	tests := []string{
+		`not json`,
+		`{"event_id":1}`,
+		`{"sent_at":"yesterday"}`,
+		`{"trace":{"sample_rate":"high"}}`,
+		`{"trace":{"sampled":"maybe"}}`,
+	}
+	for _, tt := range tests {
+		env := &Envelope{Header: json.RawMessage(tt)}
+		if _, err := env.ParseHeader(); err == nil {
+			t.Errorf("%s: expected error, got nil", tt)
+		}
+	}
+}
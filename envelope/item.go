@@ -0,0 +1,158 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/getsentry/slope/envelope/event"
+	"github.com/getsentry/slope/envelope/minidump"
+	"github.com/getsentry/slope/internal/jsonfields"
+)
+
+// Get returns the raw value of an item header field.
+func (item *Item) Get(key string) (json.RawMessage, bool) {
+	f, err := item.headerFields()
+	if err != nil {
+		return nil, false
+	}
+	return f.Get(key)
+}
+
+// Set sets an item header field, keeping the order of existing fields.
+// Setting "type" or "filename" also updates Type or Filename. A nil value
+// is set as null.
+func (item *Item) Set(key string, value any) error {
+	f, err := item.headerFields()
+	if err != nil {
+		return err
+	}
+	if err := jsonfields.Set(f, key, value, false); err != nil {
+		return fmt.Errorf("encoding %s: %w", key, err)
+	}
+	return item.setHeaderFields(f)
+}
+
+// Delete removes an item header field. Deleting "type" or "filename" also
+// clears Type or Filename.
+func (item *Item) Delete(key string) error {
+	f, err := item.headerFields()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Get(key); !ok {
+		return nil
+	}
+	f.Delete(key)
+	return item.setHeaderFields(f)
+}
+
+// Keys returns the item header field names in order.
+func (item *Item) Keys() []string {
+	f, err := item.headerFields()
+	if err != nil {
+		return nil
+	}
+	keys := make([]string, 0, f.Len())
+	for pair := f.Oldest(); pair != nil; pair = pair.Next() {
+		keys = append(keys, pair.Key)
+	}
+	return keys
+}
+
+func (item *Item) headerFields() (*jsonfields.Fields, error) {
+	if len(item.Header) == 0 {
+		return jsonfields.Copy(nil), nil
+	}
+	f, err := jsonfields.Decode(item.Header)
+	if err != nil {
+		return nil, fmt.Errorf("parsing item header: %w", err)
+	}
+	return f, nil
+}
+
+func (item *Item) setHeaderFields(f *jsonfields.Fields) error {
+	header, err := jsonfields.MarshalObject(f)
+	if err != nil {
+		return fmt.Errorf("encoding item header: %w", err)
+	}
+	if compact, err := compactJSON(item.Header); err != nil || !bytes.Equal(compact, header) {
+		item.Header = json.RawMessage(header)
+	}
+	item.Type = ""
+	item.Filename = ""
+	jsonfields.Get(f, "type", &item.Type)
+	jsonfields.Get(f, "filename", &item.Filename)
+	return nil
+}
+
+// Event decodes the payload of an event item. Transactions are events too,
+// and are decoded the same way.
+func (item *Item) Event() (*event.Event, error) {
+	if item.Type != "event" && item.Type != "transaction" {
+		return nil, fmt.Errorf("not an event item: %q", item.Type)
+	}
+	return event.Parse(item.Payload)
+}
+
+// SetEvent encodes ev as the item payload and updates the length header, if
+// present. The payload is left untouched if ev carries no changes.
+func (item *Item) SetEvent(ev *event.Event) error {
+	data, err := ev.Encode()
+	if err != nil {
+		return fmt.Errorf("encoding event: %w", err)
+	}
+	if compact, err := compactJSON(item.Payload); err == nil && bytes.Equal(compact, data) {
+		return nil
+	}
+	item.Payload = data
+	if _, ok := item.Get("length"); ok {
+		return item.Set("length", len(data))
+	}
+	return nil
+}
+
+// Session decodes the payload of a session item.
+func (item *Item) Session() (*event.Session, error) {
+	if item.Type != "session" {
+		return nil, fmt.Errorf("not a session item: %q", item.Type)
+	}
+	return event.ParseSession(item.Payload)
+}
+
+// SessionAggregates decodes the payload of a sessions item.
+func (item *Item) SessionAggregates() (*event.SessionAggregates, error) {
+	if item.Type != "sessions" {
+		return nil, fmt.Errorf("not a sessions item: %q", item.Type)
+	}
+	return event.ParseSessionAggregates(item.Payload)
+}
+
+// ClientReport decodes the payload of a client_report item.
+func (item *Item) ClientReport() (*event.ClientReport, error) {
+	if item.Type != "client_report" {
+		return nil, fmt.Errorf("not a client_report item: %q", item.Type)
+	}
+	return event.ParseClientReport(item.Payload)
+}
+
+// IsMinidump reports whether the item is a minidump attachment, by its
+// attachment_type or by the payload's signature.
+func (item *Item) IsMinidump() bool {
+	if item.Type != "attachment" {
+		return false
+	}
+	var attachmentType string
+	if data, ok := item.Get("attachment_type"); ok {
+		json.Unmarshal(data, &attachmentType)
+	}
+	return attachmentType == "event.minidump" || minidump.IsMinidump(item.Payload)
+}
+
+// Minidump decodes the payload of a minidump attachment.
+func (item *Item) Minidump() (*minidump.Minidump, error) {
+	if !item.IsMinidump() {
+		return nil, fmt.Errorf("not a minidump item: %q", item.Type)
+	}
+	return minidump.Parse(item.Payload)
+}
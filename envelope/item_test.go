@@ -0,0 +1,202 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestItemHeaderFields(t *testing.T) {
+	item := Item{Header: json.RawMessage(`{"type":"event","length":2,"id":18446744073709551615}`)}
+
+	if v, ok := item.Get("id"); !ok || string(v) != "18446744073709551615" {
+		t.Errorf("get id = %s, %v", v, ok)
+	}
+	if _, ok := item.Get("missing"); ok {
+		t.Error("get missing: ok = true")
+	}
+
+	if err := item.Set("type", "attachment"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := item.Set("filename", "a.txt"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Type != "attachment" || item.Filename != "a.txt" {
+		t.Errorf("type/filename = %q/%q, want attachment/a.txt", item.Type, item.Filename)
+	}
+	want := `{"type":"attachment","length":2,"id":18446744073709551615,"filename":"a.txt"}`
+	if string(item.Header) != want {
+		t.Errorf("header = %s, want %s", item.Header, want)
+	}
+	if got := item.Keys(); !reflect.DeepEqual(got, []string{"type", "length", "id", "filename"}) {
+		t.Errorf("keys = %v", got)
+	}
+
+	if err := item.Delete("filename"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Filename != "" {
+		t.Errorf("filename = %q after delete", item.Filename)
+	}
+	want = `{"type":"attachment","length":2,"id":18446744073709551615}`
+	if string(item.Header) != want {
+		t.Errorf("header = %s, want %s", item.Header, want)
+	}
+}
+
+func TestItemHeaderFieldsUnchanged(t *testing.T) {
+	header := `{ "type": "event", "length": 2 }`
+	item := Item{Header: json.RawMessage(header)}
+	if err := item.Set("length", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := item.Delete("missing"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(item.Header) != header {
+		t.Errorf("header = %s, want %s", item.Header, header)
+	}
+}
+
+func TestItemHeaderFieldsEmpty(t *testing.T) {
+	var item Item
+	if err := item.Set("type", "session"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(item.Header) != `{"type":"session"}` || item.Type != "session" {
+		t.Errorf("header = %s, type = %q", item.Header, item.Type)
+	}
+}
+
+func TestItemHeaderFieldsInvalid(t *testing.T) {
+	item := Item{Header: json.RawMessage("not json")}
+	if err := item.Set("type", "event"); err == nil {
+		t.Error("set: expected error, got nil")
+	}
+	if err := item.Delete("type"); err == nil {
+		t.Error("delete: expected error, got nil")
+	}
+	if item.Keys() != nil {
+		t.Error("keys: expected nil")
+	}
+	if _, ok := item.Get("type"); ok {
+		t.Error("get: ok = true")
+	}
+	if err := (&Item{}).Set("bad", func() {}); err == nil {
+		t.Error("unencodable value: expected error, got nil")
+	}
+}
+
+func TestItemSetKeepsHTMLCharacters(t *testing.T) {
+	item := &Item{Header: json.RawMessage(`{"type":"attachment","filename":"a&b<c>.txt","length":1}`)}
+	if err := item.Set("length", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"type":"attachment","filename":"a&b<c>.txt","length":2}`; string(item.Header) != want {
+		t.Errorf("header = %s, want %s", item.Header, want)
+	}
+}
+
+func TestItemSetNil(t *testing.T) {
+	item := &Item{Header: json.RawMessage(`{"type":"attachment","foo":1}`)}
+	if err := item.Set("foo", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"type":"attachment","foo":null}`; string(item.Header) != want {
+		t.Errorf("header = %s, want %s", item.Header, want)
+	}
+}
+
+func TestItemEvent(t *testing.T) {
+	for _, name := range []string{"inproc.envelope", "sigsegv.envelope"} {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			env, err := Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			item := &env.Items[0]
+			ev, err := item.Event()
+			if err != nil {
+				t.Fatalf("event error: %v", err)
+			}
+			if ev.Level != "fatal" || len(ev.Exception) != 1 || ev.Exception[0].Type != "SIGSEGV" {
+				t.Errorf("level %q, exception %+v", ev.Level, ev.Exception)
+			}
+
+			payload := item.Payload
+			_, hadLength := item.Get("length")
+			if err := item.SetEvent(ev); err != nil {
+				t.Fatalf("set event error: %v", err)
+			}
+			if &item.Payload[0] != &payload[0] {
+				t.Error("unchanged event rewrote the payload")
+			}
+
+			ev.Exception[0].Value = "Segmentation fault"
+			if err := item.SetEvent(ev); err != nil {
+				t.Fatalf("set event error: %v", err)
+			}
+			if !bytes.Contains(item.Payload, []byte(`"value":"Segmentation fault"`)) {
+				t.Errorf("payload = %s", item.Payload)
+			}
+			length, hasLength := item.Get("length")
+			if hadLength != hasLength {
+				t.Errorf("length present = %v, want %v", hasLength, hadLength)
+			}
+			if hasLength && string(length) != strconv.Itoa(len(item.Payload)) {
+				t.Errorf("length = %s, want %d", length, len(item.Payload))
+			}
+		})
+	}
+}
+
+func TestItemEventWrongType(t *testing.T) {
+	item := NewAttachmentItem("a.txt", "", "", []byte("{}"))
+	if _, err := item.Event(); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestItemMinidump(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "breakpad.envelope"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	item := &env.Items[2]
+	if !item.IsMinidump() {
+		t.Fatal("IsMinidump = false")
+	}
+	md, err := item.Minidump()
+	if err != nil {
+		t.Fatalf("minidump error: %v", err)
+	}
+	if len(md.Modules) != 34 {
+		t.Errorf("modules = %d, want 34", len(md.Modules))
+	}
+
+	// Detected by signature without an attachment_type
+	plain := NewAttachmentItem("crash.dmp", "", "", item.Payload)
+	if !plain.IsMinidump() {
+		t.Error("IsMinidump by signature = false")
+	}
+	if env.Items[0].IsMinidump() {
+		t.Error("event IsMinidump = true")
+	}
+	text := NewAttachmentItem("a.txt", "", "", []byte("hello"))
+	if _, err := text.Minidump(); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,44 @@
+package envelope
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ParseLenient parses an envelope like Parse, but recovers from malformed
+// input instead of failing. Problems are reported as diagnostics alongside
+// the partial envelope, and damaged items are marked as broken:
+//
+//   - an invalid envelope header is kept as is
+//   - an invalid item header is kept as a broken item whose payload spans up
+//     to the next line that looks like an item header
+//   - a payload whose length overruns the input is cut at the next line that
+//     looks like an item header, or kept truncated at the end of input
+//
+// An error is returned only if reading or decompressing r fails.
+func ParseLenient(r io.Reader) (*Envelope, []*ParseError, error) {
+	var diags []*ParseError
+	er, err := newReader(r, func(err *ParseError) bool {
+		diags = append(diags, err)
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	env, err := readEnvelope(er)
+	if err != nil {
+		return nil, diags, err
+	}
+	return env, diags, nil
+}
+
+func looksLikeItemHeader(line []byte) bool {
+	var hdr struct {
+		Type   string `json:"type"`
+		Length *int   `json:"length"`
+	}
+	if json.Unmarshal(line, &hdr) != nil {
+		return false
+	}
+	return hdr.Type != "" && (hdr.Length == nil || *hdr.Length >= 0)
+}
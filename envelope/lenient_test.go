@@ -0,0 +1,209 @@
+package envelope
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLenientTestdata(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.envelope"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("reading file: %v", err)
+			}
+			want, err := Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Skipf("parse error: %v", err)
+			}
+			env, diags, err := ParseLenient(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, d := range diags {
+				if d.Kind != KindMissingNewline {
+					t.Errorf("diagnostic = %v, want none but missing newlines, which Parse accepts", d)
+				}
+			}
+			if len(env.Items) != len(want.Items) {
+				t.Fatalf("item count = %d, want %d", len(env.Items), len(want.Items))
+			}
+			for i := range env.Items {
+				if env.Items[i].Type != want.Items[i].Type || !bytes.Equal(env.Items[i].Payload, want.Items[i].Payload) {
+					t.Errorf("item %d differs from Parse", i)
+				}
+				if env.Items[i].Broken() {
+					t.Errorf("item %d is broken", i)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLenientRecovery(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		types    []string
+		payloads []string
+		broken   []bool
+		kinds    []ErrorKind
+	}{
+		{
+			"invalid item header",
+			"{}\n" +
+				`{"type":"event","length":2}` + "\n" +
+				"{}\n" +
+				"not json\n" +
+				"garbage\n" +
+				`{"type":"session"}` + "\n" +
+				`{"sid":"x"}` + "\n",
+			[]string{"event", "", "session"},
+			[]string{"{}", "garbage", `{"sid":"x"}`},
+			[]bool{false, true, false},
+			[]ErrorKind{KindInvalidHeader},
+		},
+		{
+			"invalid header directly before item",
+			"{}\n" +
+				"not json\n" +
+				`{"type":"session"}` + "\n" +
+				`{"sid":"x"}` + "\n",
+			[]string{"", "session"},
+			[]string{"", `{"sid":"x"}`},
+			[]bool{true, false},
+			[]ErrorKind{KindInvalidHeader},
+		},
+		{
+			"length overrun",
+			"{}\n" +
+				`{"type":"event","length":100}` + "\n" +
+				"{}\n" +
+				`{"type":"session"}` + "\n" +
+				`{"sid":"x"}` + "\n",
+			[]string{"event", "session"},
+			[]string{"{}", `{"sid":"x"}`},
+			[]bool{true, false},
+			[]ErrorKind{KindTruncatedPayload},
+		},
+		{
+			"truncated at end",
+			"{}\n" +
+				`{"type":"attachment","length":100}` + "\n" +
+				"hello\n",
+			[]string{"attachment"},
+			[]string{"hello"},
+			[]bool{true},
+			[]ErrorKind{KindTruncatedPayload},
+		},
+		{
+			"negative length",
+			"{}\n" +
+				`{"type":"event","length":-1}` + "\n" +
+				"{}\n",
+			[]string{"event"},
+			[]string{"{}"},
+			[]bool{true},
+			[]ErrorKind{KindInvalidLength},
+		},
+		{
+			"no newline after payload",
+			"{}\n" +
+				`{"type":"event","length":2}` + "\n" +
+				`{}{"type":"session"}` + "\n" +
+				`{"sid":"x"}` + "\n",
+			[]string{"event", "session"},
+			[]string{"{}", `{"sid":"x"}`},
+			[]bool{false, false},
+			[]ErrorKind{KindMissingNewline},
+		},
+		{
+			"invalid envelope header",
+			"not json\n" +
+				`{"type":"session"}` + "\n" +
+				`{"sid":"x"}` + "\n",
+			[]string{"session"},
+			[]string{`{"sid":"x"}`},
+			[]bool{false},
+			[]ErrorKind{KindInvalidHeader},
+		},
+		{
+			"empty input",
+			"",
+			nil,
+			nil,
+			nil,
+			[]ErrorKind{KindEmptyHeader},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, diags, err := ParseLenient(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(env.Items) != len(tt.types) {
+				t.Fatalf("item count = %d, want %d", len(env.Items), len(tt.types))
+			}
+			for i, item := range env.Items {
+				if item.Type != tt.types[i] {
+					t.Errorf("item %d type = %q, want %q", i, item.Type, tt.types[i])
+				}
+				if string(item.Payload) != tt.payloads[i] {
+					t.Errorf("item %d payload = %q, want %q", i, item.Payload, tt.payloads[i])
+				}
+				if item.Broken() != tt.broken[i] {
+					t.Errorf("item %d broken = %v, want %v", i, item.Broken(), tt.broken[i])
+				}
+			}
+			if len(diags) != len(tt.kinds) {
+				t.Fatalf("diagnostics = %v, want %v", diags, tt.kinds)
+			}
+			for i, d := range diags {
+				if d.Kind != tt.kinds[i] {
+					t.Errorf("diagnostic %d kind = %s, want %s", i, d.Kind, tt.kinds[i])
+				}
+			}
+
+			// Unmodified broken input is written back as is
+			var buf bytes.Buffer
+			if err := env.SerializeLossless(&buf); err != nil {
+				t.Fatalf("serialize error: %v", err)
+			}
+			if buf.String() != tt.input {
+				t.Errorf("lossless got:\n%q\nwant:\n%q", buf.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestParseLenientDiagnosticLocation(t *testing.T) {
+	input := "{}\n" +
+		`{"type":"session"}` + "\n" +
+		"{}\n" +
+		"not json\n"
+	_, diags, err := ParseLenient(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(diags) != 1 {
+		t.Fatalf("diagnostics = %d, want 1", len(diags))
+	}
+	d := diags[0]
+	if d.Offset != 25 || d.Line != 4 || d.Item != 1 {
+		t.Errorf("offset/line/item = %d/%d/%d, want 25/4/1", d.Offset, d.Line, d.Item)
+	}
+}
+
+func TestParseLenientReadError(t *testing.T) {
+	if _, _, err := ParseLenient(errReader{}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
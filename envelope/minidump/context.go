@@ -0,0 +1,153 @@
+package minidump
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Register is a named register of a thread context.
+type Register struct {
+	Name  string
+	Value uint64
+}
+
+// Context is the CPU state of a thread. Registers are listed in their
+// conventional order, with the instruction pointer, stack pointer and
+// flags last.
+type Context struct {
+	Arch      Arch
+	Registers []Register
+}
+
+// Register returns the value of the named register.
+func (c *Context) Register(name string) (uint64, bool) {
+	for _, r := range c.Registers {
+		if r.Name == name {
+			return r.Value, true
+		}
+	}
+	return 0, false
+}
+
+// PC returns the instruction pointer.
+func (c *Context) PC() uint64 {
+	name := "pc"
+	if c.Arch == ArchAMD64 {
+		name = "rip"
+	}
+	pc, _ := c.Register(name)
+	return pc
+}
+
+// SP returns the stack pointer.
+func (c *Context) SP() uint64 {
+	name := "sp"
+	if c.Arch == ArchAMD64 {
+		name = "rsp"
+	}
+	sp, _ := c.Register(name)
+	return sp
+}
+
+// PointerSize returns the size of a pointer in bytes.
+func (a Arch) PointerSize() int {
+	switch a {
+	case ArchX86, ArchARM, ArchMIPS, ArchPPC, ArchRISCV:
+		return 4
+	}
+	return 8
+}
+
+// Offsets of the general purpose registers in the Windows CONTEXT structure
+// for x86-64, which Breakpad and Crashpad use on all platforms.
+var amd64Registers = []struct {
+	name   string
+	offset int
+}{
+	{"rax", 0x78}, {"rbx", 0x90}, {"rcx", 0x80}, {"rdx", 0x88},
+	{"rsi", 0xa8}, {"rdi", 0xb0}, {"rbp", 0xa0},
+	{"r8", 0xb8}, {"r9", 0xc0}, {"r10", 0xc8}, {"r11", 0xd0},
+	{"r12", 0xd8}, {"r13", 0xe0}, {"r14", 0xe8}, {"r15", 0xf0},
+	{"rip", 0xf8}, {"rsp", 0x98},
+}
+
+// ThreadContext decodes the register context of a thread. The context of
+// the crashed thread is taken from the exception, as its entry in the thread
+// list holds the state of the crash handler.
+func (md *Minidump) ThreadContext(t *Thread) (*Context, error) {
+	if md.SystemInfo == nil {
+		return nil, fmt.Errorf("thread %d context: no system info", t.ID)
+	}
+	loc := t.Context
+	if md.Exception != nil && md.Exception.ThreadID == t.ID && md.Exception.Context.Size > 0 {
+		loc = md.Exception.Context
+	}
+	data, err := md.Bytes(loc)
+	if err != nil {
+		return nil, fmt.Errorf("thread %d context: %w", t.ID, err)
+	}
+	ctx, err := decodeContext(md.SystemInfo.Arch, data)
+	if err != nil {
+		return nil, fmt.Errorf("thread %d context: %w", t.ID, err)
+	}
+	return ctx, nil
+}
+
+func decodeContext(arch Arch, data []byte) (*Context, error) {
+	ctx := &Context{Arch: arch}
+	u64 := func(offset int) uint64 { return binary.LittleEndian.Uint64(data[offset:]) }
+	u32 := func(offset int) uint64 { return uint64(binary.LittleEndian.Uint32(data[offset:])) }
+
+	switch arch {
+	case ArchAMD64:
+		if len(data) < 0x100 {
+			return nil, fmt.Errorf("x86_64 context of %d bytes is too short", len(data))
+		}
+		for _, r := range amd64Registers {
+			ctx.Registers = append(ctx.Registers, Register{r.name, u64(r.offset)})
+		}
+		ctx.Registers = append(ctx.Registers, Register{"eflags", u32(0x44)})
+	case ArchARM64:
+		// Flags and cpsr, followed by x0-x28, fp, lr, sp and pc
+		if len(data) < 0x110 {
+			return nil, fmt.Errorf("arm64 context of %d bytes is too short", len(data))
+		}
+		ctx.Registers = arm64Registers(func(i int) uint64 { return u64(8 + i*8) })
+		ctx.Registers = append(ctx.Registers, Register{"cpsr", u32(4)})
+	case ArchARM64Old:
+		// 64-bit flags, followed by the same registers and cpsr
+		if len(data) < 0x114 {
+			return nil, fmt.Errorf("arm64 context of %d bytes is too short", len(data))
+		}
+		ctx.Registers = arm64Registers(func(i int) uint64 { return u64(8 + i*8) })
+		ctx.Registers = append(ctx.Registers, Register{"cpsr", u32(0x110)})
+	default:
+		return nil, fmt.Errorf("unsupported architecture %s", arch)
+	}
+	return ctx, nil
+}
+
+// arm64Registers names the 33 consecutive registers x0-x28, fp, lr, sp and
+// pc, which both context layouts share.
+func arm64Registers(reg func(int) uint64) []Register {
+	var regs []Register
+	for i := range 29 {
+		regs = append(regs, Register{fmt.Sprintf("x%d", i), reg(i)})
+	}
+	return append(regs,
+		Register{"fp", reg(29)},
+		Register{"lr", reg(30)},
+		Register{"pc", reg(32)},
+		Register{"sp", reg(31)},
+	)
+}
+
+// StackMemory returns the captured stack memory of a thread, which starts
+// at t.Stack.Start.
+func (md *Minidump) StackMemory(t *Thread) ([]byte, error) {
+	data, err := md.Bytes(t.Stack.Location)
+	if err != nil {
+		return nil, fmt.Errorf("thread %d stack: %w", t.ID, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,103 @@
+package minidump
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestThreadContextAMD64(t *testing.T) {
+	md, err := Parse(readDump(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The crashed thread's context comes from the exception
+	crashed := md.Thread(md.Exception.ThreadID)
+	ctx, err := md.ThreadContext(crashed)
+	if err != nil {
+		t.Fatalf("context error: %v", err)
+	}
+	if ctx.PC() != md.Exception.Address || ctx.SP() != 0x14fb08 {
+		t.Errorf("pc = %#x, sp = %#x", ctx.PC(), ctx.SP())
+	}
+	if len(ctx.Registers) != 18 || ctx.Registers[0].Name != "rax" || ctx.Registers[17].Name != "eflags" {
+		t.Errorf("registers = %v", ctx.Registers)
+	}
+	if rdi, ok := ctx.Register("rdi"); !ok || rdi != 0x14fdf8 {
+		t.Errorf("rdi = %#x, %v", rdi, ok)
+	}
+
+	other := md.Thread(31424)
+	ctx, err = md.ThreadContext(other)
+	if err != nil {
+		t.Fatalf("context error: %v", err)
+	}
+	if ctx.PC() != 0x7ffe53573cc4 || ctx.SP() != 0x1a1fe38 {
+		t.Errorf("pc = %#x, sp = %#x", ctx.PC(), ctx.SP())
+	}
+
+	stack, err := md.StackMemory(other)
+	if err != nil {
+		t.Fatalf("stack error: %v", err)
+	}
+	if len(stack) != 456 {
+		t.Errorf("stack = %d bytes, want 456", len(stack))
+	}
+}
+
+func TestDecodeContextARM64(t *testing.T) {
+	for _, tt := range []struct {
+		arch       Arch
+		size, cpsr int
+	}{
+		{ArchARM64, 912, 4},
+		{ArchARM64Old, 0x114, 0x110},
+	} {
+		t.Run(tt.arch.String(), func(t *testing.T) {
+			data := make([]byte, tt.size)
+			for i := range 33 {
+				binary.LittleEndian.PutUint64(data[8+i*8:], uint64(0x1000+i))
+			}
+			binary.LittleEndian.PutUint32(data[tt.cpsr:], 0x60000000)
+
+			ctx, err := decodeContext(tt.arch, data)
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if len(ctx.Registers) != 34 {
+				t.Fatalf("registers = %d, want 34", len(ctx.Registers))
+			}
+			for name, want := range map[string]uint64{
+				"x0":   0x1000,
+				"x28":  0x1000 + 28,
+				"fp":   0x1000 + 29,
+				"lr":   0x1000 + 30,
+				"sp":   0x1000 + 31,
+				"pc":   0x1000 + 32,
+				"cpsr": 0x60000000,
+			} {
+				if got, _ := ctx.Register(name); got != want {
+					t.Errorf("%s = %#x, want %#x", name, got, want)
+				}
+			}
+			if ctx.PC() != 0x1020 || ctx.SP() != 0x101f {
+				t.Errorf("pc = %#x, sp = %#x", ctx.PC(), ctx.SP())
+			}
+		})
+	}
+}
+
+func TestDecodeContextInvalid(t *testing.T) {
+	if _, err := decodeContext(ArchAMD64, make([]byte, 16)); err == nil {
+		t.Error("short context: expected error, got nil")
+	}
+	if _, err := decodeContext(ArchMIPS, make([]byte, 1024)); err == nil {
+		t.Error("unsupported architecture: expected error, got nil")
+	}
+}
+
+func TestPointerSize(t *testing.T) {
+	if ArchAMD64.PointerSize() != 8 || ArchARM64.PointerSize() != 8 || ArchX86.PointerSize() != 4 || ArchARM.PointerSize() != 4 {
+		t.Error("unexpected pointer sizes")
+	}
+}
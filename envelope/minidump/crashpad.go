@@ -0,0 +1,181 @@
+package minidump
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// CrashpadInfo holds the report identifiers and annotations that Crashpad
+// adds to its minidumps.
+type CrashpadInfo struct {
+	ReportID    string
+	ClientID    string
+	Annotations []Annotation
+	Modules     []ModuleAnnotations
+}
+
+type Annotation struct {
+	Key   string
+	Value string
+}
+
+// ModuleAnnotations are the annotations a module registered with Crashpad.
+// Simple annotations and annotation objects are both listed in Annotations.
+type ModuleAnnotations struct {
+	Module      int // index into Minidump.Modules
+	List        []string
+	Annotations []Annotation
+}
+
+// Crashpad annotation object types
+const annotationString = 1
+
+func (md *Minidump) decodeCrashpadInfo(loc Location) error {
+	var raw struct {
+		Version           uint32
+		ReportID          [16]byte
+		ClientID          [16]byte
+		SimpleAnnotations Location
+		ModuleList        Location
+	}
+	if err := md.decode(loc, &raw); err != nil {
+		return err
+	}
+	info := &CrashpadInfo{ReportID: guid(raw.ReportID[:]), ClientID: guid(raw.ClientID[:])}
+	var err error
+	if info.Annotations, err = md.simpleAnnotations(raw.SimpleAnnotations); err != nil {
+		return fmt.Errorf("simple annotations: %w", err)
+	}
+	if raw.ModuleList.Size > 0 {
+		if info.Modules, err = md.moduleAnnotations(raw.ModuleList); err != nil {
+			return fmt.Errorf("module annotations: %w", err)
+		}
+	}
+	md.Crashpad = info
+	return nil
+}
+
+func (md *Minidump) moduleAnnotations(loc Location) ([]ModuleAnnotations, error) {
+	entries, count, err := md.list(loc, 12)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]struct {
+		Module uint32
+		Info   Location
+	}, count)
+	if err := md.decode(entries, links); err != nil {
+		return nil, err
+	}
+
+	var modules []ModuleAnnotations
+	for _, link := range links {
+		var raw struct {
+			Version           uint32
+			ListAnnotations   Location
+			SimpleAnnotations Location
+			AnnotationObjects Location
+		}
+		if err := md.decode(link.Info, &raw); err != nil {
+			return nil, fmt.Errorf("module %d: %w", link.Module, err)
+		}
+		m := ModuleAnnotations{Module: int(link.Module)}
+		if m.List, err = md.listAnnotations(raw.ListAnnotations); err != nil {
+			return nil, fmt.Errorf("module %d: %w", link.Module, err)
+		}
+		if m.Annotations, err = md.simpleAnnotations(raw.SimpleAnnotations); err != nil {
+			return nil, fmt.Errorf("module %d: %w", link.Module, err)
+		}
+		objects, err := md.annotationObjects(raw.AnnotationObjects)
+		if err != nil {
+			return nil, fmt.Errorf("module %d: %w", link.Module, err)
+		}
+		m.Annotations = append(m.Annotations, objects...)
+		modules = append(modules, m)
+	}
+	return modules, nil
+}
+
+// listAnnotations reads a list of RVAs to strings.
+func (md *Minidump) listAnnotations(loc Location) ([]string, error) {
+	if loc.Size == 0 {
+		return nil, nil
+	}
+	entries, count, err := md.list(loc, 4)
+	if err != nil {
+		return nil, err
+	}
+	rvas := make([]uint32, count)
+	if err := md.decode(entries, rvas); err != nil {
+		return nil, err
+	}
+	list := make([]string, count)
+	for i, rva := range rvas {
+		if list[i], err = md.utf8String(rva); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// simpleAnnotations reads a dictionary of RVAs to key and value strings.
+func (md *Minidump) simpleAnnotations(loc Location) ([]Annotation, error) {
+	if loc.Size == 0 {
+		return nil, nil
+	}
+	entries, count, err := md.list(loc, 8)
+	if err != nil {
+		return nil, err
+	}
+	rvas := make([]struct{ Key, Value uint32 }, count)
+	if err := md.decode(entries, rvas); err != nil {
+		return nil, err
+	}
+	annotations := make([]Annotation, count)
+	for i, entry := range rvas {
+		if annotations[i].Key, err = md.utf8String(entry.Key); err != nil {
+			return nil, err
+		}
+		if annotations[i].Value, err = md.utf8String(entry.Value); err != nil {
+			return nil, err
+		}
+	}
+	return annotations, nil
+}
+
+// annotationObjects reads typed annotations. Values that are not strings
+// are shown in hex.
+func (md *Minidump) annotationObjects(loc Location) ([]Annotation, error) {
+	if loc.Size == 0 {
+		return nil, nil
+	}
+	entries, count, err := md.list(loc, 12)
+	if err != nil {
+		return nil, err
+	}
+	raw := make([]struct {
+		Name  uint32
+		Type  uint16
+		_     uint16
+		Value uint32
+	}, count)
+	if err := md.decode(entries, raw); err != nil {
+		return nil, err
+	}
+	annotations := make([]Annotation, count)
+	for i, entry := range raw {
+		if annotations[i].Key, err = md.utf8String(entry.Name); err != nil {
+			return nil, err
+		}
+		value, err := md.byteArray(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Type == annotationString {
+			annotations[i].Value = string(value)
+		} else {
+			annotations[i].Value = hex.EncodeToString(value)
+		}
+	}
+	return annotations, nil
+}
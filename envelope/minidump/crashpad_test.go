@@ -0,0 +1,108 @@
+package minidump
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// dumpBuilder lays out a minidump with a single stream.
+type dumpBuilder struct {
+	data []byte
+}
+
+func newDumpBuilder(typ StreamType) *dumpBuilder {
+	b := &dumpBuilder{}
+	b.add(Header{Signature: 0x504d444d, NumberOfStreams: 1, StreamDirectoryRVA: 32})
+	b.add(Stream{Type: typ})
+	return b
+}
+
+func (b *dumpBuilder) add(values ...any) Location {
+	rva := len(b.data)
+	for _, v := range values {
+		var err error
+		if b.data, err = binary.Append(b.data, binary.LittleEndian, v); err != nil {
+			panic(err)
+		}
+	}
+	return Location{Size: uint32(len(b.data) - rva), RVA: uint32(rva)}
+}
+
+func (b *dumpBuilder) utf8(s string) uint32 {
+	loc := b.add(uint32(len(s)))
+	b.data = append(b.data, s...)
+	b.data = append(b.data, 0)
+	return loc.RVA
+}
+
+func (b *dumpBuilder) dictionary(pairs ...string) Location {
+	rvas := []uint32{uint32(len(pairs) / 2)}
+	for _, s := range pairs {
+		rvas = append(rvas, b.utf8(s))
+	}
+	return b.add(rvas)
+}
+
+// finish points the stream at loc.
+func (b *dumpBuilder) finish(loc Location) []byte {
+	binary.LittleEndian.PutUint32(b.data[32+4:], loc.Size)
+	binary.LittleEndian.PutUint32(b.data[32+8:], loc.RVA)
+	return b.data
+}
+
+func TestParseCrashpadInfo(t *testing.T) {
+	b := newDumpBuilder(StreamCrashpadInfo)
+	simple := b.dictionary("channel", "beta", "sentry[release]", "app@1.0")
+
+	list := b.add([]uint32{2, b.utf8("first"), b.utf8("second")})
+	moduleSimple := b.dictionary("ver", "3")
+	objName, objValue, binName := b.utf8("sentry"), b.utf8("enabled"), b.utf8("blob")
+	binValue := b.add([]byte{3, 0, 0, 0, 0xde, 0xad, 0xbe}).RVA
+	objects := b.add([]uint32{2, objName, 1, objValue, binName, 0x8000, binValue})
+	info := b.add(uint32(1), list, moduleSimple, objects)
+	modules := b.add(uint32(1), uint32(7), info)
+
+	reportID := [16]byte{0x78, 0x56, 0x34, 0x12, 0x34, 0x12, 0x78, 0x56, 1, 2, 3, 4, 5, 6, 7, 8}
+	stream := b.add(uint32(1), reportID, [16]byte{}, simple, modules)
+
+	md, err := Parse(b.finish(stream))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(md.Errors) > 0 {
+		t.Fatalf("errors = %v", md.Errors)
+	}
+	want := &CrashpadInfo{
+		ReportID: "12345678-1234-5678-0102-030405060708",
+		ClientID: "00000000-0000-0000-0000-000000000000",
+		Annotations: []Annotation{
+			{"channel", "beta"},
+			{"sentry[release]", "app@1.0"},
+		},
+		Modules: []ModuleAnnotations{{
+			Module: 7,
+			List:   []string{"first", "second"},
+			Annotations: []Annotation{
+				{"ver", "3"},
+				{"sentry", "enabled"},
+				{"blob", "deadbe"},
+			},
+		}},
+	}
+	if !reflect.DeepEqual(md.Crashpad, want) {
+		t.Errorf("crashpad = %+v, want %+v", md.Crashpad, want)
+	}
+}
+
+func TestParseCrashpadInfoTruncated(t *testing.T) {
+	b := newDumpBuilder(StreamCrashpadInfo)
+	stream := b.add(uint32(1), [16]byte{}, [16]byte{}, Location{Size: 12, RVA: 0xffff}, Location{})
+	md, err := Parse(b.finish(stream))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(md.Errors) != 1 || md.Crashpad != nil {
+		t.Errorf("errors = %v, crashpad = %+v", md.Errors, md.Crashpad)
+	}
+}
@@ -0,0 +1,232 @@
+// Package minidump decodes the minidumps that Breakpad and Crashpad attach
+// to native crash events.
+package minidump
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf16"
+)
+
+// Signature is the magic that every minidump starts with.
+var Signature = []byte("MDMP")
+
+// IsMinidump reports whether data starts with the minidump signature.
+func IsMinidump(data []byte) bool {
+	return bytes.HasPrefix(data, Signature)
+}
+
+type Header struct {
+	Signature          uint32
+	Version            uint32
+	NumberOfStreams    uint32
+	StreamDirectoryRVA uint32
+	Checksum           uint32
+	TimeDateStamp      uint32
+	Flags              uint64
+}
+
+// Time returns the time at which the minidump was written.
+func (h Header) Time() time.Time {
+	return time.Unix(int64(h.TimeDateStamp), 0).UTC()
+}
+
+// Location is the size and offset of a range of the minidump.
+type Location struct {
+	Size uint32
+	RVA  uint32
+}
+
+// MemoryDescriptor is a range of process memory captured in the minidump.
+type MemoryDescriptor struct {
+	Start uint64
+	Location
+}
+
+type Stream struct {
+	Type StreamType
+	Location
+}
+
+// Minidump is a decoded minidump. Streams that fail to decode are reported
+// in Errors and leave their fields empty.
+type Minidump struct {
+	Header     Header
+	Streams    []Stream
+	SystemInfo *SystemInfo
+	Exception  *Exception
+	Threads    []Thread
+	Modules    []Module
+	Crashpad   *CrashpadInfo
+	Errors     []error
+
+	data []byte
+}
+
+var errOutOfBounds = errors.New("out of bounds")
+
+// Parse decodes a minidump. An error is returned only if the header or the
+// stream directory is invalid.
+func Parse(data []byte) (*Minidump, error) {
+	md := &Minidump{data: data}
+	if err := md.decode(Location{Size: 32}, &md.Header); err != nil {
+		return nil, fmt.Errorf("parsing minidump header: %w", err)
+	}
+	if !IsMinidump(data) {
+		return nil, fmt.Errorf("parsing minidump header: invalid signature %q", data[:4])
+	}
+	n := md.Header.NumberOfStreams
+	if err := md.checkArray(md.Header.StreamDirectoryRVA, uint64(n), 12); err != nil {
+		return nil, fmt.Errorf("parsing stream directory: %d streams: %w", n, err)
+	}
+	md.Streams = make([]Stream, n)
+	dir := Location{Size: n * 12, RVA: md.Header.StreamDirectoryRVA}
+	if err := md.decode(dir, md.Streams); err != nil {
+		return nil, fmt.Errorf("parsing stream directory: %w", err)
+	}
+
+	// Modules are decoded after system info, which tells how to form their
+	// identifiers
+	for _, typ := range []StreamType{
+		StreamSystemInfo,
+		StreamException,
+		StreamThreadList,
+		StreamThreadNames,
+		StreamModuleList,
+		StreamCrashpadInfo,
+	} {
+		s, ok := md.stream(typ)
+		if !ok {
+			continue
+		}
+		if err := md.decodeStream(s); err != nil {
+			md.Errors = append(md.Errors, fmt.Errorf("%s: %w", typ, err))
+		}
+	}
+	return md, nil
+}
+
+func (md *Minidump) stream(typ StreamType) (Stream, bool) {
+	for _, s := range md.Streams {
+		if s.Type == typ {
+			return s, true
+		}
+	}
+	return Stream{}, false
+}
+
+func (md *Minidump) decodeStream(s Stream) error {
+	switch s.Type {
+	case StreamSystemInfo:
+		return md.decodeSystemInfo(s.Location)
+	case StreamException:
+		return md.decodeException(s.Location)
+	case StreamThreadList:
+		return md.decodeThreads(s.Location)
+	case StreamThreadNames:
+		return md.decodeThreadNames(s.Location)
+	case StreamModuleList:
+		return md.decodeModules(s.Location)
+	case StreamCrashpadInfo:
+		return md.decodeCrashpadInfo(s.Location)
+	}
+	return nil
+}
+
+// Thread returns the thread with the given ID, or nil.
+func (md *Minidump) Thread(id uint32) *Thread {
+	for i := range md.Threads {
+		if md.Threads[i].ID == id {
+			return &md.Threads[i]
+		}
+	}
+	return nil
+}
+
+// Bytes returns the data at loc.
+func (md *Minidump) Bytes(loc Location) ([]byte, error) {
+	end := uint64(loc.RVA) + uint64(loc.Size)
+	if end > uint64(len(md.data)) {
+		return nil, errOutOfBounds
+	}
+	return md.data[loc.RVA:end], nil
+}
+
+// checkArray checks that count entries of size bytes at rva are within the
+// minidump, before space is allocated for them.
+func (md *Minidump) checkArray(rva uint32, count, size uint64) error {
+	if count > uint64(len(md.data))/max(size, 1) || uint64(rva)+count*size > uint64(len(md.data)) {
+		return errOutOfBounds
+	}
+	return nil
+}
+
+// decode decodes the fixed-size structure at loc into v.
+func (md *Minidump) decode(loc Location, v any) error {
+	data, err := md.Bytes(loc)
+	if err != nil {
+		return err
+	}
+	_, err = binary.Decode(data, binary.LittleEndian, v)
+	return err
+}
+
+func (md *Minidump) uint32(rva uint32) (uint32, error) {
+	var v uint32
+	err := md.decode(Location{Size: 4, RVA: rva}, &v)
+	return v, err
+}
+
+// list locates the entries of a list stream, which starts with a count.
+// Some writers pad the count to align the entries to 8 bytes.
+func (md *Minidump) list(loc Location, entrySize uint32) (Location, int, error) {
+	count, err := md.uint32(loc.RVA)
+	if err != nil {
+		return Location{}, 0, err
+	}
+	size := uint64(count) * uint64(entrySize)
+	offset := uint32(4)
+	if uint64(loc.Size) == 8+size {
+		offset = 8
+	}
+	if uint64(loc.Size) < uint64(offset)+size {
+		return Location{}, 0, fmt.Errorf("%d entries overrun the stream", count)
+	}
+	if uint64(loc.RVA)+uint64(offset)+size > uint64(len(md.data)) {
+		return Location{}, 0, fmt.Errorf("%d entries: %w", count, errOutOfBounds)
+	}
+	return Location{Size: uint32(size), RVA: loc.RVA + offset}, int(count), nil
+}
+
+// utf16String reads a length-prefixed UTF-16 string.
+func (md *Minidump) utf16String(rva uint32) (string, error) {
+	n, err := md.uint32(rva)
+	if err != nil {
+		return "", err
+	}
+	if err := md.checkArray(rva, 4+uint64(n), 1); err != nil {
+		return "", err
+	}
+	units := make([]uint16, n/2)
+	if err := md.decode(Location{Size: n / 2 * 2, RVA: rva + 4}, units); err != nil {
+		return "", err
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// utf8String reads a length-prefixed UTF-8 string, as written by Crashpad.
+func (md *Minidump) utf8String(rva uint32) (string, error) {
+	data, err := md.byteArray(rva)
+	return string(data), err
+}
+
+func (md *Minidump) byteArray(rva uint32) ([]byte, error) {
+	n, err := md.uint32(rva)
+	if err != nil {
+		return nil, err
+	}
+	return md.Bytes(Location{Size: n, RVA: rva + 4})
+}
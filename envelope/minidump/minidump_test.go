@@ -0,0 +1,158 @@
+package minidump
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func readDump(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "breakpad.dmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParse(t *testing.T) {
+	md, err := Parse(readDump(t))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(md.Errors) > 0 {
+		t.Errorf("errors = %v", md.Errors)
+	}
+	if got, want := md.Header.Time(), time.Date(2024, 10, 21, 20, 38, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("time = %v, want %v", got, want)
+	}
+	if len(md.Streams) != 15 || md.Streams[0].Type != StreamThreadList {
+		t.Errorf("streams = %+v", md.Streams)
+	}
+
+	info := md.SystemInfo
+	if info == nil || info.Platform != PlatformWindows || info.Arch != ArchAMD64 || info.Processors != 12 || info.OSVersion() != "10.0.22621" {
+		t.Errorf("system info = %+v", info)
+	}
+
+	ex := md.Exception
+	if ex == nil || ex.ThreadID != 12996 || ex.Address != 0x7ffe2c7f0476 || ex.Name(PlatformWindows) != "EXCEPTION_ACCESS_VIOLATION" {
+		t.Fatalf("exception = %+v", ex)
+	}
+	if len(ex.Parameters) != 2 {
+		t.Errorf("parameters = %v", ex.Parameters)
+	}
+
+	if len(md.Threads) != 5 {
+		t.Fatalf("threads = %d, want 5", len(md.Threads))
+	}
+	if th := md.Thread(31424); th == nil || th.Name != "sentry-http" {
+		t.Errorf("thread 31424 = %+v", th)
+	}
+	if th := md.Thread(ex.ThreadID); th == nil || th.Stack.Size != 5800 {
+		t.Errorf("crashed thread = %+v", th)
+	}
+
+	if len(md.Modules) != 34 {
+		t.Fatalf("modules = %d, want 34", len(md.Modules))
+	}
+	exe := md.Modules[0]
+	if exe.Name != `D:\Sentry\Native\sentry-native\cmake-build-debug\sentry_example.exe` ||
+		exe.CodeID != "6716BAF517000" ||
+		exe.DebugID != "ed775832-b988-4ede-a32e-4a105d0e9057-2" ||
+		exe.DebugFile != `D:\Sentry\Native\sentry-native\cmake-build-debug\sentry_example.pdb` {
+		t.Errorf("module 0 = %+v", exe)
+	}
+	if ntdll := md.Modules[1]; ntdll.Version != "6.2.22621.4317" || ntdll.DebugFile != "ntdll.pdb" {
+		t.Errorf("module 1 = %+v", ntdll)
+	}
+	if m := md.ModuleAt(ex.Address); m == nil || m.Name != `C:\Windows\System32\VCRUNTIME140D.dll` {
+		t.Errorf("module at exception address = %+v", m)
+	}
+	if m := md.ModuleAt(0x10); m != nil {
+		t.Errorf("module at 0x10 = %+v", m)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	data := readDump(t)
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"short":     data[:16],
+		"signature": append([]byte("MDMX"), data[4:]...),
+		"directory": data[:64],
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(data); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestParseHugeCounts(t *testing.T) {
+	header := func(streams uint32) []byte {
+		data := make([]byte, 32)
+		copy(data, Signature)
+		binary.LittleEndian.PutUint32(data[8:], streams)
+		binary.LittleEndian.PutUint32(data[12:], 32)
+		return data
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := Parse(header(100_000_000))
+	runtime.ReadMemStats(&after)
+	if err == nil {
+		t.Error("stream count: expected error, got nil")
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("stream count: allocated %d bytes", n)
+	}
+
+	// A thread list that claims to span the address space, with a count to
+	// match, and a string as long
+	data := header(1)
+	data = binary.LittleEndian.AppendUint32(data, uint32(StreamThreadList))
+	data = binary.LittleEndian.AppendUint32(data, 0xffffffff)
+	data = binary.LittleEndian.AppendUint32(data, 44)
+	data = binary.LittleEndian.AppendUint32(data, 0xffffffff/48)
+	md, err := Parse(data)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(md.Errors) != 1 || md.Threads != nil {
+		t.Errorf("errors = %v, threads = %d", md.Errors, len(md.Threads))
+	}
+	if _, err := md.utf16String(44); err == nil {
+		t.Error("string length: expected error, got nil")
+	}
+}
+
+func TestParseStreamError(t *testing.T) {
+	data := append([]byte(nil), readDump(t)...)
+	// Point the module list (second directory entry) past the end
+	binary.LittleEndian.PutUint32(data[32+12+8:], uint32(len(data)))
+
+	md, err := Parse(data)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(md.Errors) != 1 || md.Modules != nil {
+		t.Errorf("errors = %v, modules = %d", md.Errors, len(md.Modules))
+	}
+	if len(md.Threads) != 5 || md.Exception == nil {
+		t.Error("other streams were not decoded")
+	}
+}
+
+func TestIsMinidump(t *testing.T) {
+	if !IsMinidump(readDump(t)) {
+		t.Error("IsMinidump = false")
+	}
+	if IsMinidump([]byte("{}")) {
+		t.Error("IsMinidump = true")
+	}
+}
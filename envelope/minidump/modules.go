@@ -0,0 +1,131 @@
+package minidump
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// Module is a loaded executable or shared library. CodeID and DebugID are
+// formed like the code_id and debug_id of Sentry's debug_meta images.
+type Module struct {
+	Base          uint64
+	Size          uint32
+	Checksum      uint32
+	TimeDateStamp uint32
+	Name          string
+	Version       string
+	CodeID        string
+	DebugID       string
+	DebugFile     string
+}
+
+// Contains reports whether addr falls into the module's image.
+func (m *Module) Contains(addr uint64) bool {
+	return addr >= m.Base && addr-m.Base < uint64(m.Size)
+}
+
+type rawModule struct {
+	Base          uint64
+	Size          uint32
+	Checksum      uint32
+	TimeDateStamp uint32
+	NameRVA       uint32
+	Version       [13]uint32 // VS_FIXEDFILEINFO
+	CVRecord      Location
+	MiscRecord    Location
+	_             [2]uint64
+}
+
+// CodeView record signatures
+const (
+	cvSignaturePDB70 = 0x53445352 // "RSDS"
+	cvSignatureELF   = 0x4270454c // "LEpB", written by Breakpad for ELF modules
+)
+
+func (md *Minidump) decodeModules(loc Location) error {
+	entries, count, err := md.list(loc, 108)
+	if err != nil {
+		return err
+	}
+	raw := make([]rawModule, count)
+	if err := md.decode(entries, raw); err != nil {
+		return err
+	}
+	md.Modules = make([]Module, count)
+	for i, r := range raw {
+		m := Module{
+			Base:          r.Base,
+			Size:          r.Size,
+			Checksum:      r.Checksum,
+			TimeDateStamp: r.TimeDateStamp,
+		}
+		if m.Name, err = md.utf16String(r.NameRVA); err != nil {
+			return fmt.Errorf("module %d name: %w", i, err)
+		}
+		if r.Version[0] == 0xfeef04bd && (r.Version[2] != 0 || r.Version[3] != 0) {
+			m.Version = fmt.Sprintf("%d.%d.%d.%d", r.Version[2]>>16, r.Version[2]&0xffff, r.Version[3]>>16, r.Version[3]&0xffff)
+		}
+		if md.SystemInfo != nil && md.SystemInfo.Platform == PlatformWindows {
+			m.CodeID = fmt.Sprintf("%08X%x", r.TimeDateStamp, r.Size)
+		}
+		if r.CVRecord.Size >= 4 {
+			cv, err := md.Bytes(r.CVRecord)
+			if err != nil {
+				return fmt.Errorf("module %d codeview record: %w", i, err)
+			}
+			m.decodeCodeView(cv)
+		}
+		md.Modules[i] = m
+	}
+	return nil
+}
+
+// decodeCodeView takes the debug identifiers from a CodeView record.
+// Records of other formats are ignored.
+func (m *Module) decodeCodeView(cv []byte) {
+	switch binary.LittleEndian.Uint32(cv) {
+	case cvSignaturePDB70:
+		// GUID, age and debug file name
+		if len(cv) < 24 {
+			return
+		}
+		m.DebugID = guid(cv[4:20])
+		if age := binary.LittleEndian.Uint32(cv[20:]); age != 0 {
+			m.DebugID += fmt.Sprintf("-%x", age)
+		}
+		m.DebugFile = string(bytes.TrimRight(cv[24:], "\x00"))
+	case cvSignatureELF:
+		// GNU build ID, whose first 16 bytes form the debug ID
+		buildID := cv[4:]
+		if len(buildID) == 0 {
+			return
+		}
+		m.CodeID = hex.EncodeToString(buildID)
+		var id [16]byte
+		copy(id[:], buildID)
+		m.DebugID = guid(id[:])
+		m.DebugFile = m.Name
+	}
+}
+
+// guid formats 16 bytes as a Microsoft GUID, whose first three fields are
+// little-endian.
+func guid(b []byte) string {
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(b),
+		binary.LittleEndian.Uint16(b[4:]),
+		binary.LittleEndian.Uint16(b[6:]),
+		b[8:10], b[10:16])
+}
+
+// ModuleAt returns the module whose image contains addr, or nil.
+func (md *Minidump) ModuleAt(addr uint64) *Module {
+	for i := range md.Modules {
+		if md.Modules[i].Contains(addr) {
+			return &md.Modules[i]
+		}
+	}
+	return nil
+}
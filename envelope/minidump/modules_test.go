@@ -0,0 +1,37 @@
+package minidump
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestDecodeCodeViewELF(t *testing.T) {
+	buildID, _ := hex.DecodeString("f1c3bcc0279865fe3058404b2831d9e64135386c")
+	m := Module{Name: "/usr/lib/libc.so.6"}
+	m.decodeCodeView(append([]byte("LEpB"), buildID...))
+	if m.CodeID != "f1c3bcc0279865fe3058404b2831d9e64135386c" {
+		t.Errorf("code id = %q", m.CodeID)
+	}
+	if m.DebugID != "c0bcc3f1-9827-fe65-3058-404b2831d9e6" {
+		t.Errorf("debug id = %q", m.DebugID)
+	}
+	if m.DebugFile != m.Name {
+		t.Errorf("debug file = %q", m.DebugFile)
+	}
+}
+
+func TestDecodeCodeViewShortBuildID(t *testing.T) {
+	m := Module{}
+	m.decodeCodeView([]byte("LEpB\x01\x02\x03\x04"))
+	if m.CodeID != "01020304" || m.DebugID != "04030201-0000-0000-0000-000000000000" {
+		t.Errorf("code id = %q, debug id = %q", m.CodeID, m.DebugID)
+	}
+}
+
+func TestDecodeCodeViewUnknown(t *testing.T) {
+	m := Module{}
+	m.decodeCodeView([]byte("NB10\x00\x00\x00\x00"))
+	if m.CodeID != "" || m.DebugID != "" {
+		t.Errorf("code id = %q, debug id = %q", m.CodeID, m.DebugID)
+	}
+}
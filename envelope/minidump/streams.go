@@ -0,0 +1,408 @@
+package minidump
+
+import (
+	"fmt"
+	"strings"
+)
+
+type StreamType uint32
+
+const (
+	StreamThreadList      StreamType = 3
+	StreamModuleList      StreamType = 4
+	StreamMemoryList      StreamType = 5
+	StreamException       StreamType = 6
+	StreamSystemInfo      StreamType = 7
+	StreamMemory64List    StreamType = 9
+	StreamUnloadedModules StreamType = 14
+	StreamMiscInfo        StreamType = 15
+	StreamMemoryInfoList  StreamType = 16
+	StreamThreadInfoList  StreamType = 17
+	StreamThreadNames     StreamType = 24
+	StreamCrashpadInfo    StreamType = 0x43500001
+	StreamBreakpadInfo    StreamType = 0x47670001
+	StreamAssertionInfo   StreamType = 0x47670002
+	StreamLinuxCPUInfo    StreamType = 0x47670003
+	StreamLinuxProcStatus StreamType = 0x47670004
+	StreamLinuxLSBRelease StreamType = 0x47670005
+	StreamLinuxCmdLine    StreamType = 0x47670006
+	StreamLinuxEnviron    StreamType = 0x47670007
+	StreamLinuxAuxv       StreamType = 0x47670008
+	StreamLinuxMaps       StreamType = 0x47670009
+	StreamLinuxDSODebug   StreamType = 0x4767000a
+)
+
+var streamNames = map[StreamType]string{
+	0:                     "Unused",
+	StreamThreadList:      "ThreadList",
+	StreamModuleList:      "ModuleList",
+	StreamMemoryList:      "MemoryList",
+	StreamException:       "Exception",
+	StreamSystemInfo:      "SystemInfo",
+	8:                     "ThreadExList",
+	StreamMemory64List:    "Memory64List",
+	10:                    "CommentA",
+	11:                    "CommentW",
+	12:                    "HandleData",
+	13:                    "FunctionTable",
+	StreamUnloadedModules: "UnloadedModuleList",
+	StreamMiscInfo:        "MiscInfo",
+	StreamMemoryInfoList:  "MemoryInfoList",
+	StreamThreadInfoList:  "ThreadInfoList",
+	18:                    "HandleOperationList",
+	19:                    "Token",
+	20:                    "JavaScriptData",
+	21:                    "SystemMemoryInfo",
+	22:                    "ProcessVmCounters",
+	23:                    "IptTrace",
+	StreamThreadNames:     "ThreadNames",
+	StreamCrashpadInfo:    "CrashpadInfo",
+	StreamBreakpadInfo:    "BreakpadInfo",
+	StreamAssertionInfo:   "AssertionInfo",
+	StreamLinuxCPUInfo:    "LinuxCpuInfo",
+	StreamLinuxProcStatus: "LinuxProcStatus",
+	StreamLinuxLSBRelease: "LinuxLsbRelease",
+	StreamLinuxCmdLine:    "LinuxCmdLine",
+	StreamLinuxEnviron:    "LinuxEnviron",
+	StreamLinuxAuxv:       "LinuxAuxv",
+	StreamLinuxMaps:       "LinuxMaps",
+	StreamLinuxDSODebug:   "LinuxDsoDebug",
+}
+
+func (t StreamType) String() string {
+	if name, ok := streamNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%x", uint32(t))
+}
+
+// Arch is the processor architecture of the crashed process.
+type Arch uint16
+
+const (
+	ArchX86      Arch = 0
+	ArchMIPS     Arch = 1
+	ArchPPC      Arch = 3
+	ArchARM      Arch = 5
+	ArchAMD64    Arch = 9
+	ArchARM64    Arch = 12
+	ArchSPARC    Arch = 0x8001
+	ArchPPC64    Arch = 0x8002
+	ArchARM64Old Arch = 0x8003
+	ArchMIPS64   Arch = 0x8004
+	ArchRISCV    Arch = 0x8005
+	ArchRISCV64  Arch = 0x8006
+	ArchUnknown  Arch = 0xffff
+)
+
+// String returns the architecture name as used in Sentry events.
+func (a Arch) String() string {
+	switch a {
+	case ArchX86:
+		return "x86"
+	case ArchMIPS:
+		return "mips"
+	case ArchPPC:
+		return "ppc"
+	case ArchARM:
+		return "arm"
+	case ArchAMD64:
+		return "x86_64"
+	case ArchARM64, ArchARM64Old:
+		return "arm64"
+	case ArchSPARC:
+		return "sparc"
+	case ArchPPC64:
+		return "ppc64"
+	case ArchMIPS64:
+		return "mips64"
+	case ArchRISCV:
+		return "riscv"
+	case ArchRISCV64:
+		return "riscv64"
+	}
+	return fmt.Sprintf("unknown (0x%x)", uint16(a))
+}
+
+// Platform is the operating system of the crashed process.
+type Platform uint32
+
+const (
+	PlatformWindows Platform = 2
+	PlatformUnix    Platform = 0x8000
+	PlatformMacOS   Platform = 0x8101
+	PlatformIOS     Platform = 0x8102
+	PlatformLinux   Platform = 0x8201
+	PlatformSolaris Platform = 0x8202
+	PlatformAndroid Platform = 0x8203
+	PlatformPS3     Platform = 0x8204
+	PlatformNaCl    Platform = 0x8205
+	PlatformFuchsia Platform = 0x8206
+)
+
+func (p Platform) String() string {
+	switch p {
+	case PlatformWindows:
+		return "Windows"
+	case PlatformUnix:
+		return "Unix"
+	case PlatformMacOS:
+		return "macOS"
+	case PlatformIOS:
+		return "iOS"
+	case PlatformLinux:
+		return "Linux"
+	case PlatformSolaris:
+		return "Solaris"
+	case PlatformAndroid:
+		return "Android"
+	case PlatformPS3:
+		return "PS3"
+	case PlatformNaCl:
+		return "NaCl"
+	case PlatformFuchsia:
+		return "Fuchsia"
+	}
+	return fmt.Sprintf("unknown (0x%x)", uint32(p))
+}
+
+type SystemInfo struct {
+	Arch              Arch
+	ProcessorLevel    uint16
+	ProcessorRevision uint16
+	Processors        int
+	ProductType       uint8
+	MajorVersion      uint32
+	MinorVersion      uint32
+	BuildNumber       uint32
+	Platform          Platform
+	CSDVersion        string // service pack on Windows, kernel version elsewhere
+	CPUVendor         string // x86 only
+}
+
+// OSVersion returns the dotted major, minor and build number.
+func (s *SystemInfo) OSVersion() string {
+	return fmt.Sprintf("%d.%d.%d", s.MajorVersion, s.MinorVersion, s.BuildNumber)
+}
+
+type rawSystemInfo struct {
+	Arch              Arch
+	ProcessorLevel    uint16
+	ProcessorRevision uint16
+	Processors        uint8
+	ProductType       uint8
+	MajorVersion      uint32
+	MinorVersion      uint32
+	BuildNumber       uint32
+	Platform          Platform
+	CSDVersionRVA     uint32
+	SuiteMask         uint16
+	_                 uint16
+	CPU               [24]byte
+}
+
+func (md *Minidump) decodeSystemInfo(loc Location) error {
+	var raw rawSystemInfo
+	if err := md.decode(loc, &raw); err != nil {
+		return err
+	}
+	info := &SystemInfo{
+		Arch:              raw.Arch,
+		ProcessorLevel:    raw.ProcessorLevel,
+		ProcessorRevision: raw.ProcessorRevision,
+		Processors:        int(raw.Processors),
+		ProductType:       raw.ProductType,
+		MajorVersion:      raw.MajorVersion,
+		MinorVersion:      raw.MinorVersion,
+		BuildNumber:       raw.BuildNumber,
+		Platform:          raw.Platform,
+	}
+	if raw.CSDVersionRVA != 0 {
+		csd, err := md.utf16String(raw.CSDVersionRVA)
+		if err != nil {
+			return fmt.Errorf("csd version: %w", err)
+		}
+		info.CSDVersion = csd
+	}
+	// x86 processors store the CPUID vendor string, others store feature bits
+	if vendor := string(raw.CPU[:12]); (raw.Arch == ArchX86 || raw.Arch == ArchAMD64) && isPrintable(vendor) {
+		info.CPUVendor = vendor
+	}
+	md.SystemInfo = info
+	return nil
+}
+
+func isPrintable(s string) bool {
+	return s != "" && !strings.ContainsFunc(s, func(r rune) bool { return r < 0x20 || r > 0x7e })
+}
+
+// Exception is the exception or signal that caused the crash.
+type Exception struct {
+	ThreadID   uint32
+	Code       uint32
+	Flags      uint32
+	Address    uint64
+	Parameters []uint64
+	Context    Location
+}
+
+type rawException struct {
+	ThreadID   uint32
+	_          uint32
+	Code       uint32
+	Flags      uint32
+	Record     uint64
+	Address    uint64
+	NumParams  uint32
+	_          uint32
+	Parameters [15]uint64
+	Context    Location
+}
+
+func (md *Minidump) decodeException(loc Location) error {
+	var raw rawException
+	if err := md.decode(loc, &raw); err != nil {
+		return err
+	}
+	md.Exception = &Exception{
+		ThreadID:   raw.ThreadID,
+		Code:       raw.Code,
+		Flags:      raw.Flags,
+		Address:    raw.Address,
+		Parameters: raw.Parameters[:min(raw.NumParams, 15)],
+		Context:    raw.Context,
+	}
+	return nil
+}
+
+var (
+	windowsExceptions = map[uint32]string{
+		0x80000003: "EXCEPTION_BREAKPOINT",
+		0x80000004: "EXCEPTION_SINGLE_STEP",
+		0xc0000005: "EXCEPTION_ACCESS_VIOLATION",
+		0xc0000006: "EXCEPTION_IN_PAGE_ERROR",
+		0xc0000008: "EXCEPTION_INVALID_HANDLE",
+		0xc000001d: "EXCEPTION_ILLEGAL_INSTRUCTION",
+		0xc0000025: "EXCEPTION_NONCONTINUABLE_EXCEPTION",
+		0xc000008c: "EXCEPTION_ARRAY_BOUNDS_EXCEEDED",
+		0xc000008e: "EXCEPTION_FLT_DIVIDE_BY_ZERO",
+		0xc0000094: "EXCEPTION_INT_DIVIDE_BY_ZERO",
+		0xc0000095: "EXCEPTION_INT_OVERFLOW",
+		0xc0000096: "EXCEPTION_PRIV_INSTRUCTION",
+		0xc00000fd: "EXCEPTION_STACK_OVERFLOW",
+		0xc0000374: "STATUS_HEAP_CORRUPTION",
+		0xc0000409: "STATUS_STACK_BUFFER_OVERRUN",
+		0xe06d7363: "EXCEPTION_CXX",
+	}
+	unixSignals = map[uint32]string{
+		1:  "SIGHUP",
+		2:  "SIGINT",
+		3:  "SIGQUIT",
+		4:  "SIGILL",
+		5:  "SIGTRAP",
+		6:  "SIGABRT",
+		7:  "SIGBUS",
+		8:  "SIGFPE",
+		9:  "SIGKILL",
+		11: "SIGSEGV",
+		13: "SIGPIPE",
+		15: "SIGTERM",
+		31: "SIGSYS",
+	}
+	machExceptions = map[uint32]string{
+		1:  "EXC_BAD_ACCESS",
+		2:  "EXC_BAD_INSTRUCTION",
+		3:  "EXC_ARITHMETIC",
+		4:  "EXC_EMULATION",
+		5:  "EXC_SOFTWARE",
+		6:  "EXC_BREAKPOINT",
+		10: "EXC_CRASH",
+		11: "EXC_RESOURCE",
+		12: "EXC_GUARD",
+	}
+)
+
+// Name returns the symbolic name of the exception code, which depends on
+// the platform, or an empty string if it is not known.
+func (e *Exception) Name(p Platform) string {
+	switch p {
+	case PlatformWindows:
+		return windowsExceptions[e.Code]
+	case PlatformMacOS, PlatformIOS:
+		return machExceptions[e.Code]
+	}
+	return unixSignals[e.Code]
+}
+
+type Thread struct {
+	ID            uint32
+	Name          string
+	SuspendCount  uint32
+	PriorityClass uint32
+	Priority      uint32
+	TEB           uint64
+	Stack         MemoryDescriptor
+	Context       Location
+}
+
+type rawThread struct {
+	ID            uint32
+	SuspendCount  uint32
+	PriorityClass uint32
+	Priority      uint32
+	TEB           uint64
+	Stack         MemoryDescriptor
+	Context       Location
+}
+
+func (md *Minidump) decodeThreads(loc Location) error {
+	entries, count, err := md.list(loc, 48)
+	if err != nil {
+		return err
+	}
+	raw := make([]rawThread, count)
+	if err := md.decode(entries, raw); err != nil {
+		return err
+	}
+	md.Threads = make([]Thread, count)
+	for i, t := range raw {
+		md.Threads[i] = Thread{
+			ID:            t.ID,
+			SuspendCount:  t.SuspendCount,
+			PriorityClass: t.PriorityClass,
+			Priority:      t.Priority,
+			TEB:           t.TEB,
+			Stack:         t.Stack,
+			Context:       t.Context,
+		}
+	}
+	return nil
+}
+
+// decodeThreadNames names the threads of the thread list. The entries are
+// packed to 12 bytes, a 32-bit thread ID followed by a 64-bit RVA.
+func (md *Minidump) decodeThreadNames(loc Location) error {
+	entries, count, err := md.list(loc, 12)
+	if err != nil {
+		return err
+	}
+	raw := make([]struct {
+		ThreadID uint32
+		RVA      uint64
+	}, count)
+	if err := md.decode(entries, raw); err != nil {
+		return err
+	}
+	for _, entry := range raw {
+		t := md.Thread(entry.ThreadID)
+		if t == nil || entry.RVA > 0xffffffff {
+			continue
+		}
+		name, err := md.utf16String(uint32(entry.RVA))
+		if err != nil {
+			return fmt.Errorf("thread %d: %w", entry.ThreadID, err)
+		}
+		t.Name = name
+	}
+	return nil
+}
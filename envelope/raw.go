@@ -0,0 +1,88 @@
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"io"
+)
+
+// rawEnvelope records the original bytes of a parsed envelope header and
+// whatever followed the last item.
+type rawEnvelope struct {
+	header  []byte
+	newline bool
+	trailer []byte
+}
+
+// rawItem records the original framing of a parsed item.
+type rawItem struct {
+	prefix   []byte // empty lines before the header
+	header   []byte
+	newline  bool // header terminated by a newline
+	implicit bool // payload without a length
+	sum      [sha256.Size]byte
+	trailer  bool // payload terminated by a newline
+	broken   bool
+}
+
+// Broken reports whether the item was recovered from malformed input by
+// ParseLenient.
+func (item *Item) Broken() bool {
+	return item.raw != nil && item.raw.broken
+}
+
+func (item *Item) unmodified() bool {
+	return item.raw != nil &&
+		bytes.Equal(item.Header, item.raw.header) &&
+		sha256.Sum256(item.Payload) == item.raw.sum
+}
+
+// SerializeLossless writes the envelope like Serialize, except that the
+// envelope header and the items that were not modified since parsing are
+// written back byte for byte.
+func (env *Envelope) SerializeLossless(w io.Writer) error {
+	cw, err := Compress(w, env.Encoding)
+	if err != nil {
+		return err
+	}
+	ew := &Writer{w: bufio.NewWriter(cw)}
+
+	if env.raw != nil && bytes.Equal(env.Header, env.raw.header) {
+		ew.w.Write(env.raw.header)
+		if env.raw.newline || len(env.Items) > 0 {
+			ew.w.WriteByte('\n')
+		}
+	} else if err := ew.writeHeader(env.Header); err != nil {
+		return err
+	}
+
+	last := len(env.Items) - 1
+	for i, item := range env.Items {
+		if !item.unmodified() {
+			if err := ew.WriteItem(item.Header, bytes.NewReader(item.Payload), int64(len(item.Payload))); err != nil {
+				return err
+			}
+			continue
+		}
+		raw := item.raw
+		ew.w.Write(raw.prefix)
+		ew.w.Write(raw.header)
+		if raw.newline || i < last {
+			ew.w.WriteByte('\n')
+		}
+		ew.w.Write(item.Payload)
+		// An implicit-length payload must be terminated unless it is last.
+		if raw.trailer || (raw.implicit && i < last) {
+			ew.w.WriteByte('\n')
+		}
+	}
+
+	if env.raw != nil {
+		ew.w.Write(env.raw.trailer)
+	}
+	if err := ew.Flush(); err != nil {
+		return err
+	}
+	return cw.Close()
+}
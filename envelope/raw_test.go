@@ -0,0 +1,122 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSerializeLosslessTestdata(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.envelope"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("reading file: %v", err)
+			}
+			env, err := Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Skipf("parse error: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := env.SerializeLossless(&buf); err != nil {
+				t.Fatalf("serialize error: %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), data) {
+				t.Errorf("got:\n%q\nwant:\n%q", buf.Bytes(), data)
+			}
+		})
+	}
+}
+
+func TestSerializeLosslessModified(t *testing.T) {
+	input := `{ "event_id": "abc123" }` + "\n" +
+		`{ "type": "attachment" }` + "\n" +
+		"hello\n" +
+		"\n" +
+		`{"type":"event","length":2}` + "\n" +
+		`{}` +
+		`{ "type": "session" }` + "\n" +
+		`{"sid":"x"}`
+
+	tests := []struct {
+		name   string
+		modify func(env *Envelope)
+		want   string
+	}{
+		{
+			"unmodified",
+			func(env *Envelope) {},
+			input,
+		},
+		{
+			"payload",
+			func(env *Envelope) { env.Items[1].Payload = []byte(`{"a":1}`) },
+			`{ "event_id": "abc123" }` + "\n" +
+				`{ "type": "attachment" }` + "\n" +
+				"hello\n" +
+				`{"type":"event","length":7}` + "\n" +
+				`{"a":1}` + "\n" +
+				`{ "type": "session" }` + "\n" +
+				`{"sid":"x"}`,
+		},
+		{
+			"envelope header",
+			func(env *Envelope) { env.Header = json.RawMessage(`{"event_id": "def456"}`) },
+			`{"event_id":"def456"}` + "\n" + strings.SplitN(input, "\n", 2)[1],
+		},
+		{
+			"appended",
+			func(env *Envelope) {
+				env.Items = append(env.Items, Item{Header: json.RawMessage(`{"type":"attachment"}`), Payload: []byte("x")})
+			},
+			input + "\n" +
+				`{"type":"attachment","length":1}` + "\n" +
+				"x\n",
+		},
+		{
+			"deleted",
+			func(env *Envelope) { env.Items = env.Items[:1] },
+			`{ "event_id": "abc123" }` + "\n" +
+				`{ "type": "attachment" }` + "\n" +
+				"hello\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := Parse(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			tt.modify(env)
+			var buf bytes.Buffer
+			if err := env.SerializeLossless(&buf); err != nil {
+				t.Fatalf("serialize error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSerializeLosslessUnparsed(t *testing.T) {
+	env := &Envelope{
+		Header: json.RawMessage(`{ "event_id": "abc123" }`),
+		Items:  []Item{{Header: json.RawMessage(`{"type":"session"}`), Payload: []byte(`{}`)}},
+	}
+	var buf bytes.Buffer
+	if err := env.SerializeLossless(&buf); err != nil {
+		t.Fatalf("serialize error: %v", err)
+	}
+	want := `{"event_id":"abc123"}` + "\n" + `{"type":"session","length":2}` + "\n" + "{}\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,319 @@
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Reader reads an envelope item by item without buffering payloads.
+type Reader struct {
+	r        *bufio.Reader
+	encoding Encoding
+	header   json.RawMessage
+	raw      *rawEnvelope
+	payload  io.Reader
+	item     *rawItem
+	index    int
+	offset   int64
+	lines    int
+
+	// recover, if set, is called with parse errors and reports whether the
+	// reader should recover from them instead of failing.
+	recover func(*ParseError) bool
+}
+
+// NewReader reads the envelope header from r, which is transparently
+// decompressed if compressed.
+func NewReader(r io.Reader) (*Reader, error) {
+	return newReader(r, nil)
+}
+
+func newReader(r io.Reader, recover func(*ParseError) bool) (*Reader, error) {
+	dr, enc, err := Decompress(r)
+	if err != nil {
+		return nil, &ParseError{Kind: KindReadError, Line: 1, Item: -1, Err: err, msg: err.Error()}
+	}
+	er := &Reader{r: bufio.NewReader(dr), encoding: enc, index: -1, recover: recover}
+
+	// First line: envelope header
+	line, err := er.readLine()
+	if err != nil && err != io.EOF {
+		return nil, er.errorf(KindReadError, 0, 1, err, "reading envelope header: %v", err)
+	}
+	newline := bytes.HasSuffix(line, []byte("\n"))
+	line = bytes.TrimRight(line, "\n")
+	var perr *ParseError
+	switch {
+	case len(line) == 0:
+		perr = er.errorf(KindEmptyHeader, 0, 1, nil, "empty envelope header")
+	case !json.Valid(line):
+		perr = er.errorf(KindInvalidHeader, 0, 1, nil, "invalid JSON in envelope header")
+	}
+	if perr != nil && !er.recovered(perr) {
+		return nil, perr
+	}
+	er.header = json.RawMessage(line)
+	er.raw = &rawEnvelope{header: line, newline: newline}
+	return er, nil
+}
+
+func (r *Reader) Header() json.RawMessage {
+	return r.header
+}
+
+// Encoding returns the compression the envelope was read with.
+func (r *Reader) Encoding() Encoding {
+	return r.encoding
+}
+
+// Next advances to the next item and returns its header fields along with a
+// reader for its payload. Any unread part of the previous payload is skipped.
+// Next returns io.EOF when there are no more items.
+func (r *Reader) Next() (Item, io.Reader, error) {
+	if err := r.endPayload(); err != nil {
+		return Item{}, nil, err
+	}
+
+	raw := &rawItem{}
+	var headerLine []byte
+	var offset int64
+	var lineNo int
+	for len(headerLine) == 0 {
+		offset, lineNo = r.offset, r.lines+1
+		line, err := r.readLine()
+		if err != nil && err != io.EOF {
+			return Item{}, nil, r.errorf(KindReadError, offset, lineNo, err, "reading item header: %v", err)
+		}
+		if len(line) == 0 && err == io.EOF {
+			r.raw.trailer = raw.prefix
+			return Item{}, nil, io.EOF
+		}
+		raw.newline = bytes.HasSuffix(line, []byte("\n"))
+		headerLine = bytes.TrimRight(line, "\n")
+		if len(headerLine) == 0 {
+			raw.prefix = append(raw.prefix, line...) // skip empty lines (e.g., trailing newline)
+		}
+	}
+	r.index++
+	raw.header = headerLine
+	item := Item{
+		Header: json.RawMessage(headerLine),
+		raw:    raw,
+	}
+	r.item = raw
+	if !json.Valid(headerLine) {
+		return r.fail(item, r.errorf(KindInvalidHeader, offset, lineNo, nil, "invalid JSON in item header: %s", headerLine))
+	}
+
+	// Extract type, length, filename from header
+	var hdr struct {
+		Type     string `json:"type"`
+		Length   *int   `json:"length"`
+		Filename string `json:"filename"`
+	}
+	if err := json.Unmarshal(headerLine, &hdr); err != nil {
+		return r.fail(item, r.errorf(KindInvalidHeader, offset, lineNo, err, "parsing item header: %v", err))
+	}
+	item.Type = hdr.Type
+	item.Filename = hdr.Filename
+
+	if hdr.Length == nil {
+		r.payload = &lineReader{r: r}
+		raw.implicit = true
+		return item, r.payload, nil
+	}
+	if *hdr.Length < 0 {
+		return r.fail(item, r.errorf(KindInvalidLength, offset, lineNo, nil, "invalid payload length: %d", *hdr.Length))
+	}
+	r.payload = &fixedReader{r: r, length: *hdr.Length, offset: r.offset, line: r.lines + 1}
+	if r.recover == nil {
+		return item, r.payload, nil
+	}
+
+	// Read the payload up front to recover if it is truncated
+	data, err := io.ReadAll(r.payload)
+	var perr *ParseError
+	if errors.As(err, &perr) && perr.Kind == KindTruncatedPayload {
+		r.payload = nil
+		r.unread(data)
+		return r.fail(item, perr)
+	}
+	if err != nil {
+		return Item{}, nil, err
+	}
+	r.payload = bytes.NewReader(data)
+	return item, r.payload, nil
+}
+
+// recovered reports whether the reader carries on after a parse error, as
+// decided by the recover hook, if set.
+func (r *Reader) recovered(err *ParseError) bool {
+	return r.recover != nil && r.recover(err)
+}
+
+// fail returns a parse error, or recovers from it by marking the item as
+// broken and taking everything up to the next line that looks like an item
+// header as its payload.
+func (r *Reader) fail(item Item, err *ParseError) (Item, io.Reader, error) {
+	if !r.recovered(err) {
+		return Item{}, nil, err
+	}
+	var payload []byte
+	for {
+		line, err := r.readLine()
+		if err != nil && err != io.EOF {
+			return Item{}, nil, r.errorf(KindReadError, r.offset, r.lines+1, err, "reading payload: %v", err)
+		}
+		if looksLikeItemHeader(bytes.TrimSuffix(line, []byte("\n"))) {
+			r.unread(line)
+			break
+		}
+		payload = append(payload, line...)
+		if err == io.EOF {
+			item.raw.implicit = true
+			break
+		}
+	}
+	item.raw.broken = true
+	payload, item.raw.trailer = bytes.CutSuffix(payload, []byte("\n"))
+	return item, bytes.NewReader(payload), nil
+}
+
+// unread puts data that was read back in front of the input.
+func (r *Reader) unread(data []byte) {
+	r.r = bufio.NewReader(io.MultiReader(bytes.NewReader(data), r.r))
+	r.offset -= int64(len(data))
+	r.lines -= bytes.Count(data, []byte("\n"))
+}
+
+// endPayload skips any unread part of the current payload and consumes the
+// newline that terminates it. A payload of a declared length must be
+// followed by a newline or the end of input.
+func (r *Reader) endPayload() error {
+	if r.payload == nil {
+		return nil
+	}
+	payload := r.payload
+	r.payload = nil
+	if _, err := io.Copy(io.Discard, payload); err != nil {
+		return err
+	}
+	if lr, ok := payload.(*lineReader); ok {
+		r.item.trailer = lr.newline
+		return nil
+	}
+	// Consume trailing newline
+	b, err := r.r.Peek(1)
+	switch {
+	case err != nil:
+	case b[0] == '\n':
+		r.discard(1)
+		r.item.trailer = true
+	default:
+		if err := r.errorf(KindMissingNewline, r.offset, r.lines+1, nil, "missing newline after payload: got %q", b[0]); !r.recovered(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.r.ReadBytes('\n')
+	r.consumed(line)
+	return line, err
+}
+
+func (r *Reader) discard(n int) {
+	b, _ := r.r.Peek(n)
+	r.consumed(b)
+	r.r.Discard(n)
+}
+
+func (r *Reader) consumed(b []byte) {
+	r.offset += int64(len(b))
+	r.lines += bytes.Count(b, []byte("\n"))
+}
+
+func (r *Reader) errorf(kind ErrorKind, offset int64, line int, err error, format string, args ...any) *ParseError {
+	return &ParseError{
+		Kind:   kind,
+		Offset: offset,
+		Line:   line,
+		Item:   r.index,
+		Err:    err,
+		msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// fixedReader reads a payload of a declared length.
+type fixedReader struct {
+	r      *Reader
+	length int
+	read   int
+	offset int64
+	line   int
+}
+
+func (f *fixedReader) Read(p []byte) (int, error) {
+	if f.read >= f.length {
+		return 0, io.EOF
+	}
+	if len(p) > f.length-f.read {
+		p = p[:f.length-f.read]
+	}
+	n, err := f.r.r.Read(p)
+	f.r.consumed(p[:n])
+	f.read += n
+	if errors.Is(err, io.EOF) {
+		if f.read < f.length {
+			return n, f.r.errorf(KindTruncatedPayload, f.offset, f.line, nil, "payload truncated: expected %d bytes, got %d", f.length, f.read)
+		}
+		err = nil
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, f.r.errorf(KindReadError, f.r.offset, f.r.lines+1, err, "reading payload: %v", err)
+	}
+	return n, err
+}
+
+// lineReader reads an implicit-length payload up to the next newline, which
+// is consumed but not returned.
+type lineReader struct {
+	r       *Reader
+	done    bool
+	newline bool
+}
+
+func (l *lineReader) Read(p []byte) (int, error) {
+	if l.done {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	br := l.r.r
+	if br.Buffered() == 0 {
+		if _, err := br.Peek(1); err != nil {
+			l.done = true
+			if err != io.EOF {
+				err = l.r.errorf(KindReadError, l.r.offset, l.r.lines+1, err, "reading payload: %v", err)
+			}
+			return 0, err
+		}
+	}
+	buf, _ := br.Peek(min(len(p), br.Buffered()))
+	if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+		n := copy(p, buf[:i])
+		l.r.discard(i + 1)
+		l.done = true
+		l.newline = true
+		return n, io.EOF
+	}
+	n := copy(p, buf)
+	l.r.discard(n)
+	return n, nil
+}
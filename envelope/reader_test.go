@@ -0,0 +1,136 @@
+package envelope
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReaderNext(t *testing.T) {
+	input := `{"event_id":"abc123"}` + "\n" +
+		`{"type":"event","length":27}` + "\n" +
+		`{"message":"hello world!!"}` + "\n" +
+		`{"type":"attachment","filename":"test.txt"}` + "\n" +
+		"hello\n"
+
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(r.Header()) != `{"event_id":"abc123"}` {
+		t.Errorf("header = %q", r.Header())
+	}
+
+	item, payload, err := r.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Type != "event" {
+		t.Errorf("item 0 type = %q, want event", item.Type)
+	}
+	data, err := io.ReadAll(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `{"message":"hello world!!"}` {
+		t.Errorf("item 0 payload = %q", data)
+	}
+
+	item, payload, err = r.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Type != "attachment" || item.Filename != "test.txt" {
+		t.Errorf("item 1 = %q/%q, want attachment/test.txt", item.Type, item.Filename)
+	}
+	data, err = io.ReadAll(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("item 1 payload = %q, want hello", data)
+	}
+
+	if _, _, err := r.Next(); err != io.EOF {
+		t.Errorf("err = %v, want io.EOF", err)
+	}
+}
+
+func TestReaderSkipsUnreadPayload(t *testing.T) {
+	input := "{}\n" +
+		`{"type":"attachment","length":5}` + "\n" +
+		"hello\n" +
+		`{"type":"attachment"}` + "\n" +
+		"world\n" +
+		`{"type":"session"}` + "\n" +
+		`{"sid":"x"}` + "\n"
+
+	r, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var types []string
+	for {
+		item, _, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		types = append(types, item.Type)
+	}
+	if got := strings.Join(types, ","); got != "attachment,attachment,session" {
+		t.Errorf("types = %s", got)
+	}
+}
+
+func TestReaderLargePayload(t *testing.T) {
+	large := bytes.Repeat([]byte("x"), 100000)
+	var input bytes.Buffer
+	input.WriteString("{}\n")
+	input.WriteString(`{"type":"attachment"}` + "\n")
+	input.Write(large)
+	input.WriteString("\n")
+	input.WriteString(`{"type":"attachment","length":100000}` + "\n")
+	input.Write(large)
+
+	env, err := Parse(&input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(env.Items) != 2 {
+		t.Fatalf("items = %d, want 2", len(env.Items))
+	}
+	for i, item := range env.Items {
+		if !bytes.Equal(item.Payload, large) {
+			t.Errorf("item %d payload length = %d, want %d", i, len(item.Payload), len(large))
+		}
+	}
+}
+
+func TestReaderTruncatedPayload(t *testing.T) {
+	r, err := NewReader(strings.NewReader("{}\n{\"type\":\"event\",\"length\":100}\nhello\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, payload, err := r.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = io.ReadAll(payload)
+	if err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("err = %v, want truncated error", err)
+	}
+}
+
+func TestReaderInvalidLength(t *testing.T) {
+	r, err := NewReader(strings.NewReader("{}\n{\"type\":\"event\",\"length\":-1}\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := r.Next(); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
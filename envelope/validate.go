@@ -0,0 +1,313 @@
+package envelope
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Finding is a protocol violation reported by Validate.
+type Finding struct {
+	Severity Severity
+	Item     int    // item index, or -1 for the envelope header
+	Rule     string // machine-readable rule name
+	Message  string
+}
+
+func (f Finding) String() string {
+	if f.Item < 0 {
+		return fmt.Sprintf("%s: envelope: %s [%s]", f.Severity, f.Message, f.Rule)
+	}
+	return fmt.Sprintf("%s: item %d: %s [%s]", f.Severity, f.Item+1, f.Message, f.Rule)
+}
+
+// Ingestion size limits, see https://develop.sentry.dev/sdk/foundations/transport/envelopes/#size-limits
+const (
+	maxEnvelopeSize    = 200 * 1024 * 1024
+	maxEventSize       = 1024 * 1024
+	maxAttachmentSize  = 100 * 1024 * 1024
+	maxAttachmentsSize = 200 * 1024 * 1024
+	maxSessionCount    = 100
+)
+
+var knownItemTypes = map[string]bool{
+	"attachment":       true,
+	"check_in":         true,
+	"client_report":    true,
+	"event":            true,
+	"feedback":         true,
+	"log":              true,
+	"otel_log":         true,
+	"profile":          true,
+	"profile_chunk":    true,
+	"replay_event":     true,
+	"replay_recording": true,
+	"replay_video":     true,
+	"session":          true,
+	"sessions":         true,
+	"span":             true,
+	"statsd":           true,
+	"trace_metric":     true,
+	"transaction":      true,
+	"user_report":      true,
+}
+
+// Item types whose payload is a single JSON document.
+var jsonItemTypes = map[string]bool{
+	"check_in":      true,
+	"client_report": true,
+	"event":         true,
+	"feedback":      true,
+	"log":           true,
+	"profile":       true,
+	"profile_chunk": true,
+	"replay_event":  true,
+	"session":       true,
+	"sessions":      true,
+	"span":          true,
+	"transaction":   true,
+	"user_report":   true,
+}
+
+var knownAttachmentTypes = map[string]bool{
+	"event.attachment":       true,
+	"event.minidump":         true,
+	"event.applecrashreport": true,
+	"event.view_hierarchy":   true,
+	"unreal.context":         true,
+	"unreal.logs":            true,
+}
+
+var (
+	uuidPattern    = regexp.MustCompile(`^[0-9a-fA-F]{32}$|^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	traceIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+)
+
+// Validate checks an envelope against the Sentry protocol rules.
+func Validate(env *Envelope) []Finding {
+	v := &validator{}
+	v.validateHeader(env)
+
+	var total, attachments, sessions int
+	var eventItem = -1
+	updates := map[string]*event.Session{}
+	for i, item := range env.Items {
+		total += len(item.Header) + len(item.Payload) + 2
+		v.validateItem(i, item)
+
+		switch item.Type {
+		case "event", "transaction":
+			if eventItem >= 0 {
+				v.errorf(i, "duplicate-event", "at most one event or transaction per envelope, first is item %d", eventItem+1)
+			} else {
+				eventItem = i
+			}
+			v.checkEventID(env, i, item)
+		case "attachment":
+			attachments += len(item.Payload)
+		case "session":
+			sessions++
+			v.checkSession(i, item, updates)
+		case "sessions":
+			v.checkSessionAggregates(i, item)
+		}
+	}
+
+	if sessions > maxSessionCount {
+		v.errorf(-1, "size-limit", "%d session items exceed the limit of %d", sessions, maxSessionCount)
+	}
+	if attachments > maxAttachmentsSize {
+		v.errorf(-1, "size-limit", "attachments of %d bytes exceed the limit of %d", attachments, maxAttachmentsSize)
+	}
+	if total+len(env.Header) > maxEnvelopeSize {
+		v.errorf(-1, "size-limit", "envelope of %d bytes exceeds the limit of %d", total+len(env.Header), maxEnvelopeSize)
+	}
+	return v.findings
+}
+
+type validator struct {
+	findings []Finding
+}
+
+func (v *validator) errorf(item int, rule, format string, args ...any) {
+	v.findings = append(v.findings, Finding{SeverityError, item, rule, fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(item int, rule, format string, args ...any) {
+	v.findings = append(v.findings, Finding{SeverityWarning, item, rule, fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validateHeader(env *Envelope) {
+	h, err := env.ParseHeader()
+	if err != nil {
+		v.errorf(-1, "invalid-header", "%v", err)
+		return
+	}
+	if h.EventID != "" && !uuidPattern.MatchString(h.EventID) {
+		v.errorf(-1, "invalid-uuid", "event_id %q is not a valid UUID", h.EventID)
+	}
+	if h.Trace != nil {
+		if !traceIDPattern.MatchString(h.Trace.TraceID) {
+			v.errorf(-1, "invalid-trace-id", "trace.trace_id %q is not 32 hex digits", h.Trace.TraceID)
+		}
+		if h.Trace.PublicKey == "" {
+			v.errorf(-1, "missing-public-key", "trace.public_key is missing")
+		}
+	}
+}
+
+func (v *validator) validateItem(i int, item Item) {
+	var hdr struct {
+		Type           string `json:"type"`
+		Filename       string `json:"filename"`
+		AttachmentType string `json:"attachment_type"`
+	}
+	if err := json.Unmarshal(item.Header, &hdr); err != nil {
+		v.errorf(i, "invalid-header", "invalid item header: %v", err)
+		return
+	}
+
+	switch {
+	case hdr.Type == "":
+		v.errorf(i, "missing-type", "item type is missing")
+	case !knownItemTypes[hdr.Type]:
+		v.warnf(i, "unknown-type", "unknown item type %q", hdr.Type)
+	}
+
+	if jsonItemTypes[hdr.Type] && !json.Valid(item.Payload) {
+		v.errorf(i, "invalid-payload", "%s payload is not valid JSON", hdr.Type)
+	}
+
+	switch hdr.Type {
+	case "event", "transaction", "feedback", "user_report", "check_in", "span", "log":
+		if len(item.Payload) > maxEventSize {
+			v.errorf(i, "size-limit", "%s of %d bytes exceeds the limit of %d", hdr.Type, len(item.Payload), maxEventSize)
+		}
+	case "attachment":
+		if hdr.Filename == "" {
+			v.errorf(i, "missing-filename", "attachment filename is missing")
+		}
+		if hdr.AttachmentType != "" && !knownAttachmentTypes[hdr.AttachmentType] {
+			v.warnf(i, "unknown-attachment-type", "unknown attachment_type %q", hdr.AttachmentType)
+		}
+		if len(item.Payload) > maxAttachmentSize {
+			v.errorf(i, "size-limit", "attachment of %d bytes exceeds the limit of %d", len(item.Payload), maxAttachmentSize)
+		}
+	}
+}
+
+func (v *validator) checkEventID(env *Envelope, i int, item Item) {
+	var payload struct {
+		EventID string `json:"event_id"`
+	}
+	if json.Unmarshal(item.Payload, &payload) != nil || payload.EventID == "" {
+		return
+	}
+	if !uuidPattern.MatchString(payload.EventID) {
+		v.errorf(i, "invalid-uuid", "payload event_id %q is not a valid UUID", payload.EventID)
+		return
+	}
+	h, err := env.ParseHeader()
+	if err != nil {
+		return
+	}
+	if h.EventID == "" {
+		v.warnf(i, "missing-event-id", "envelope event_id is missing, payload has %q", payload.EventID)
+	} else if normalizeUUID(h.EventID) != normalizeUUID(payload.EventID) {
+		v.errorf(i, "event-id-mismatch", "payload event_id %q does not match envelope event_id %q", payload.EventID, h.EventID)
+	}
+}
+
+func normalizeUUID(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, "-", ""))
+}
+
+// checkSession checks a session update, and its transition from an earlier
+// update of the same session in the envelope.
+func (v *validator) checkSession(i int, item Item, updates map[string]*event.Session) {
+	s, err := item.Session()
+	if err != nil {
+		return
+	}
+	switch {
+	case s.SID == "":
+		v.errorf(i, "missing-session-id", "session sid is missing")
+	case !uuidPattern.MatchString(s.SID):
+		v.errorf(i, "invalid-uuid", "session sid %q is not a valid UUID", s.SID)
+	}
+	if s.Attrs.Release == "" {
+		v.errorf(i, "missing-release", "session attrs.release is missing")
+	}
+	if s.Status != "" && !slices.Contains(event.SessionStatuses, s.Status) {
+		v.errorf(i, "invalid-session-status", "unknown session status %q", s.Status)
+	}
+	if s.Errors < 0 {
+		v.errorf(i, "invalid-session", "negative error count %d", s.Errors)
+	}
+	if s.Status == "crashed" && s.Errors == 0 {
+		v.warnf(i, "invalid-session", "crashed session has no errors")
+	}
+	if s.Duration != nil && *s.Duration < 0 {
+		v.errorf(i, "invalid-session", "negative duration %v", *s.Duration)
+	}
+	if !s.Started.IsZero() && !s.Timestamp.IsZero() && s.Timestamp.Before(s.Started.Time) {
+		v.errorf(i, "invalid-session", "timestamp is before started")
+	}
+
+	if s.SID == "" {
+		return
+	}
+	prev, ok := updates[s.SID]
+	updates[s.SID] = s
+	if !ok {
+		return
+	}
+	switch {
+	case prev.Ended():
+		v.errorf(i, "session-transition", "session update after the session ended as %s", prev.Status)
+	case s.Init:
+		v.warnf(i, "session-transition", "init flag set on a later session update")
+	}
+	if prev.Seq != nil && s.Seq != nil && *s.Seq < *prev.Seq {
+		v.warnf(i, "session-transition", "seq %d is lower than %d of the previous update", *s.Seq, *prev.Seq)
+	}
+	if s.Errors < prev.Errors {
+		v.warnf(i, "session-transition", "error count decreased from %d to %d", prev.Errors, s.Errors)
+	}
+}
+
+func (v *validator) checkSessionAggregates(i int, item Item) {
+	a, err := item.SessionAggregates()
+	if err != nil {
+		return
+	}
+	if a.Attrs.Release == "" {
+		v.errorf(i, "missing-release", "sessions attrs.release is missing")
+	}
+	for n, b := range a.Aggregates {
+		if b.Started.IsZero() {
+			v.errorf(i, "invalid-session", "aggregate %d has no started time", n+1)
+		}
+		if b.Exited < 0 || b.Errored < 0 || b.Abnormal < 0 || b.Crashed < 0 {
+			v.errorf(i, "invalid-session", "aggregate %d has a negative count", n+1)
+		}
+	}
+}
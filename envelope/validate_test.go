@@ -0,0 +1,188 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func rules(findings []Finding) string {
+	var s []string
+	for _, f := range findings {
+		s = append(s, fmt.Sprintf("%s:%d:%s", f.Severity, f.Item, f.Rule))
+	}
+	return strings.Join(s, ",")
+}
+
+func TestValidateTestdata(t *testing.T) {
+	for _, file := range []string{"breakpad.envelope", "inproc.envelope", "sigsegv.envelope", "two_items.envelope"} {
+		t.Run(file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			env, err := Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Validate(env); len(got) != 0 {
+				t.Errorf("findings = %v, want none", got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const id = "9ec79c33ec9942ab8353589fcb2e04dc"
+	tests := []struct {
+		name   string
+		header string
+		items  [][2]string
+		want   string
+	}{
+		{
+			"valid",
+			`{"event_id":"` + id + `"}`,
+			[][2]string{
+				{`{"type":"event"}`, `{"event_id":"9ec79c33-ec99-42ab-8353-589fcb2e04dc"}`},
+				{`{"type":"attachment","filename":"a.txt","attachment_type":"event.attachment"}`, "hello"},
+			},
+			"",
+		},
+		{"invalid header", `not json`, nil, "error:-1:invalid-header"},
+		{"invalid event_id", `{"event_id":"xyz"}`, nil, "error:-1:invalid-uuid"},
+		{
+			"invalid trace",
+			`{"trace":{"trace_id":"abc"}}`,
+			nil,
+			"error:-1:invalid-trace-id,error:-1:missing-public-key",
+		},
+		{
+			"item types",
+			`{}`,
+			[][2]string{{`{}`, ""}, {`{"type":"foo"}`, ""}, {`not json`, ""}},
+			"error:0:missing-type,warning:1:unknown-type,error:2:invalid-header",
+		},
+		{
+			"duplicate event",
+			`{"event_id":"` + id + `"}`,
+			[][2]string{{`{"type":"event"}`, `{}`}, {`{"type":"transaction"}`, `{}`}},
+			"error:1:duplicate-event",
+		},
+		{
+			"event_id mismatch",
+			`{"event_id":"` + id + `"}`,
+			[][2]string{{`{"type":"event"}`, `{"event_id":"00000000000000000000000000000000"}`}},
+			"error:0:event-id-mismatch",
+		},
+		{
+			"event_id missing",
+			`{}`,
+			[][2]string{{`{"type":"event"}`, `{"event_id":"` + id + `"}`}},
+			"warning:0:missing-event-id",
+		},
+		{
+			"invalid payload event_id",
+			`{}`,
+			[][2]string{{`{"type":"event"}`, `{"event_id":"nope"}`}},
+			"error:0:invalid-uuid",
+		},
+		{
+			"invalid payload",
+			`{}`,
+			[][2]string{{`{"type":"session"}`, `{`}},
+			"error:0:invalid-payload",
+		},
+		{
+			"session",
+			`{}`,
+			[][2]string{
+				{`{"type":"session"}`, `{"sid":"` + id + `","init":true,"status":"ok","errors":0,"attrs":{"release":"app@1.0"}}`},
+				{`{"type":"session"}`, `{"sid":"` + id + `","status":"crashed","errors":1,"attrs":{"release":"app@1.0"}}`},
+			},
+			"",
+		},
+		{
+			"invalid session",
+			`{}`,
+			[][2]string{
+				{`{"type":"session"}`, `{"status":"gone","errors":-1,"duration":-1}`},
+				{`{"type":"session"}`, `{"sid":"nope","status":"crashed","started":"2025-01-01T00:00:01Z","timestamp":"2025-01-01T00:00:00Z","attrs":{"release":"app@1.0"}}`},
+			},
+			"error:0:missing-session-id,error:0:missing-release,error:0:invalid-session-status,error:0:invalid-session,error:0:invalid-session," +
+				"error:1:invalid-uuid,warning:1:invalid-session,error:1:invalid-session",
+		},
+		{
+			"session transitions",
+			`{}`,
+			[][2]string{
+				{`{"type":"session"}`, `{"sid":"` + id + `","seq":2,"status":"ok","errors":2,"attrs":{"release":"app@1.0"}}`},
+				{`{"type":"session"}`, `{"sid":"` + id + `","seq":1,"init":true,"status":"exited","errors":1,"attrs":{"release":"app@1.0"}}`},
+				{`{"type":"session"}`, `{"sid":"` + id + `","seq":3,"status":"ok","errors":1,"attrs":{"release":"app@1.0"}}`},
+			},
+			"warning:1:session-transition,warning:1:session-transition,warning:1:session-transition,error:2:session-transition",
+		},
+		{
+			"sessions",
+			`{}`,
+			[][2]string{
+				{`{"type":"sessions"}`, `{"aggregates":[{"started":"2025-01-01T00:00:00Z","exited":2}],"attrs":{"release":"app@1.0"}}`},
+				{`{"type":"sessions"}`, `{"aggregates":[{"crashed":-1}]}`},
+			},
+			"error:1:missing-release,error:1:invalid-session,error:1:invalid-session",
+		},
+		{
+			"attachment",
+			`{}`,
+			[][2]string{{`{"type":"attachment","attachment_type":"event.foo"}`, "x"}},
+			"error:0:missing-filename,warning:0:unknown-attachment-type",
+		},
+		{
+			"event size",
+			`{}`,
+			[][2]string{{`{"type":"event"}`, `{"x":"` + strings.Repeat("x", maxEventSize) + `"}`}},
+			"error:0:size-limit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &Envelope{Header: json.RawMessage(tt.header)}
+			for _, it := range tt.items {
+				item := Item{Header: json.RawMessage(it[0]), Payload: []byte(it[1])}
+				if v, ok := item.Get("type"); ok {
+					json.Unmarshal(v, &item.Type)
+				}
+				env.Items = append(env.Items, item)
+			}
+			if got := rules(Validate(env)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSessionCount(t *testing.T) {
+	env := &Envelope{Header: json.RawMessage(`{}`)}
+	for i := range maxSessionCount + 1 {
+		payload := fmt.Sprintf(`{"sid":"%032x","attrs":{"release":"app@1.0"}}`, i)
+		env.Items = append(env.Items, Item{Header: json.RawMessage(`{"type":"session"}`), Payload: []byte(payload), Type: "session"})
+	}
+	if got := rules(Validate(env)); got != "error:-1:size-limit" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestFindingString(t *testing.T) {
+	f := Finding{SeverityError, 1, "missing-filename", "attachment filename is missing"}
+	if got, want := f.String(), "error: item 2: attachment filename is missing [missing-filename]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	f = Finding{SeverityWarning, -1, "invalid-uuid", "bad"}
+	if got, want := f.String(), "warning: envelope: bad [invalid-uuid]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package envelope
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Writer writes an envelope item by item, streaming payloads from readers.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter writes the envelope header to w and returns a Writer for the
+// items that follow. Call Flush when done.
+func NewWriter(w io.Writer, header json.RawMessage) (*Writer, error) {
+	ew := &Writer{w: bufio.NewWriter(w)}
+	if err := ew.writeHeader(header); err != nil {
+		return nil, err
+	}
+	return ew, nil
+}
+
+func (w *Writer) writeHeader(header json.RawMessage) error {
+	// Write envelope header (compact)
+	compact, err := compactJSON(header)
+	if err != nil {
+		return fmt.Errorf("compacting envelope header: %w", err)
+	}
+	w.w.Write(compact)
+	return w.w.WriteByte('\n')
+}
+
+// WriteItem writes an item header followed by length bytes read from payload.
+// The length field of the header is updated to match. If length is negative,
+// it is computed from payload, which is buffered in memory only if it
+// implements neither Len nor io.Seeker.
+func (w *Writer) WriteItem(header json.RawMessage, payload io.Reader, length int64) error {
+	if length < 0 {
+		var err error
+		payload, length, err = payloadLength(payload)
+		if err != nil {
+			return fmt.Errorf("computing payload length: %w", err)
+		}
+	}
+
+	// Update length in header
+	updated, err := UpdateLength(header, int(length))
+	if err != nil {
+		return fmt.Errorf("updating item header: %w", err)
+	}
+	compact, err := compactJSON(updated)
+	if err != nil {
+		return fmt.Errorf("compacting item header: %w", err)
+	}
+	w.w.Write(compact)
+	w.w.WriteByte('\n')
+
+	n, err := io.Copy(w.w, io.LimitReader(payload, length))
+	if err != nil {
+		return fmt.Errorf("writing payload: %w", err)
+	}
+	if n != length {
+		return fmt.Errorf("payload truncated: expected %d bytes, got %d", length, n)
+	}
+	return w.w.WriteByte('\n')
+}
+
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+func payloadLength(r io.Reader) (io.Reader, int64, error) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return r, int64(v.Len()), nil
+	case io.Seeker:
+		cur, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, 0, err
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, err := v.Seek(cur, io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+		return r, end - cur, nil
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
@@ -0,0 +1,72 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.bin")
+	if err := os.WriteFile(path, []byte{0x00, 0x01, 0x02, 0x03}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, json.RawMessage(`{ "event_id": "abc123" }`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.WriteItem(json.RawMessage(`{"type":"event"}`), strings.NewReader(`{"message":"hi"}`), 16); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.WriteItem(json.RawMessage(`{"type":"attachment","filename":"dump.bin"}`), f, -1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.WriteItem(json.RawMessage(`{"type":"attachment"}`), io.MultiReader(strings.NewReader("hello")), -1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"event_id":"abc123"}` + "\n" +
+		`{"type":"event","length":16}` + "\n" +
+		`{"message":"hi"}` + "\n" +
+		`{"type":"attachment","filename":"dump.bin","length":4}` + "\n" +
+		"\x00\x01\x02\x03\n" +
+		`{"type":"attachment","length":5}` + "\n" +
+		"hello\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%q\nwant:\n%q", buf.String(), want)
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	if _, err := NewWriter(io.Discard, json.RawMessage("not json")); err == nil {
+		t.Error("invalid envelope header: expected error, got nil")
+	}
+
+	w, err := NewWriter(io.Discard, json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.WriteItem(json.RawMessage("not json"), strings.NewReader("x"), 1); err == nil {
+		t.Error("invalid item header: expected error, got nil")
+	}
+	if err := w.WriteItem(json.RawMessage(`{"type":"event"}`), strings.NewReader("x"), 10); err == nil {
+		t.Error("short payload: expected error, got nil")
+	}
+	if err := w.WriteItem(json.RawMessage(`{"type":"event"}`), errReader{}, -1); err == nil {
+		t.Error("read error: expected error, got nil")
+	}
+}
@@ -0,0 +1,174 @@
+// Package jsonfields keeps the members of JSON objects in their original
+// order and encoding, so that objects round-trip unchanged except for the
+// members that are modelled and modified.
+package jsonfields
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// Fields holds the members of a JSON object in their original order, with
+// values kept raw so that unknown members round-trip unchanged.
+type Fields = orderedmap.OrderedMap[string, json.RawMessage]
+
+// Decode decodes the members of a JSON object.
+func Decode(data []byte) (*Fields, error) {
+	f := orderedmap.New[string, json.RawMessage]()
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Copy returns a copy of f, or an empty object if f is nil.
+func Copy(f *Fields) *Fields {
+	c := orderedmap.New[string, json.RawMessage]()
+	if f != nil {
+		for pair := f.Oldest(); pair != nil; pair = pair.Next() {
+			c.Set(pair.Key, pair.Value)
+		}
+	}
+	return c
+}
+
+// Get decodes the member key into v, if present.
+func Get(f *Fields, key string, v any) error {
+	if data, ok := f.Get(key); ok {
+		return json.Unmarshal(data, v)
+	}
+	return nil
+}
+
+// Set stores v as the member key, or removes the member if omit is set. A
+// member whose decoded value equals v keeps its original encoding. A nil v
+// is stored as null.
+func Set(f *Fields, key string, v any, omit bool) error {
+	if omit {
+		f.Delete(key)
+		return nil
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	if old, ok := f.Get(key); ok && v != nil {
+		if prev, ok := reencode(old, reflect.TypeOf(v)); ok && bytes.Equal(prev, data) {
+			return nil
+		}
+	}
+	f.Set(key, data)
+	return nil
+}
+
+// DecodeStruct decodes a JSON object into the tagged fields of the struct
+// that v points to and returns all members for re-encoding. Members that do
+// not decode into their field are left zero, but are kept.
+func DecodeStruct(data []byte, v any) (*Fields, error) {
+	f, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	s := reflect.ValueOf(v).Elem()
+	s.SetZero()
+	for i := range s.NumField() {
+		key := fieldKey(s.Type().Field(i))
+		if key == "" {
+			continue
+		}
+		if raw, ok := f.Get(key); ok {
+			json.Unmarshal(raw, s.Field(i).Addr().Interface())
+		}
+	}
+	return f, nil
+}
+
+// EncodeStruct encodes the tagged fields of the struct that v points to on
+// top of the members in f. Zero fields are omitted. Members whose decoded
+// value is unchanged keep their original encoding, and so do members that
+// did not decode in the first place unless their field has been set.
+func EncodeStruct(v any, f *Fields) ([]byte, error) {
+	out := Copy(f)
+	s := reflect.ValueOf(v).Elem()
+	for i := range s.NumField() {
+		key := fieldKey(s.Type().Field(i))
+		if key == "" {
+			continue
+		}
+		value := s.Field(i)
+		data, err := Marshal(value.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if old, ok := out.Get(key); ok {
+			prev, ok := reencode(old, value.Type())
+			if !ok && value.IsZero() || ok && bytes.Equal(prev, data) {
+				continue
+			}
+		}
+		if value.IsZero() {
+			out.Delete(key)
+		} else {
+			out.Set(key, data)
+		}
+	}
+	return MarshalObject(out)
+}
+
+// reencode decodes a member as type t and encodes it again, to compare it
+// with a new value. It reports whether the member decodes.
+func reencode(old json.RawMessage, t reflect.Type) ([]byte, bool) {
+	prev := reflect.New(t)
+	if json.Unmarshal(old, prev.Interface()) != nil {
+		return nil, false
+	}
+	data, err := Marshal(prev.Elem().Interface())
+	return data, err == nil
+}
+
+// MarshalObject encodes the members of an object in order, compacted but
+// otherwise as they are.
+func MarshalObject(f *Fields) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for pair := f.Oldest(); pair != nil; pair = pair.Next() {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, err := Marshal(pair.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		if err := json.Compact(&buf, pair.Value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Marshal encodes v without escaping HTML characters, which payloads keep
+// as they are.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func fieldKey(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return key
+}
@@ -0,0 +1,55 @@
+package jsonfields
+
+import "testing"
+
+func TestSet(t *testing.T) {
+	f, err := Decode([]byte(`{"a":1.0,"b":"x","c":true}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tt := range []struct {
+		key  string
+		v    any
+		omit bool
+	}{
+		{"a", 1.0, false},
+		{"b", "<y>", false},
+		{"c", nil, true},
+		{"d", []string{"z"}, false},
+	} {
+		if err := Set(f, tt.key, tt.v, tt.omit); err != nil {
+			t.Fatalf("Set(%q): %v", tt.key, err)
+		}
+	}
+	data, err := MarshalObject(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"a":1.0,"b":"<y>","d":["z"]}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestStruct(t *testing.T) {
+	type object struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	var v object
+	f, err := DecodeStruct([]byte(`{"x":[1, 2],"count":"many","name":"a"}`), &v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Name != "a" || v.Count != 0 {
+		t.Errorf("decoded %+v", v)
+	}
+	data, err := EncodeStruct(&v, f)
+	if err != nil || string(data) != `{"x":[1,2],"count":"many","name":"a"}` {
+		t.Errorf("unchanged = %s, %v", data, err)
+	}
+	v.Name, v.Count = "", 3
+	data, err = EncodeStruct(&v, f)
+	if err != nil || string(data) != `{"x":[1,2],"count":3}` {
+		t.Errorf("changed = %s, %v", data, err)
+	}
+}
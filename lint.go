@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/getsentry/slope/envelope"
+)
+
+// lint reports parse diagnostics and protocol findings for each file and
+// returns the exit status: 1 if any errors were found, 0 otherwise.
+func lint(w io.Writer, paths []string) int {
+	status := 0
+	for _, path := range paths {
+		env, diags, _, err := readEnvelope(path)
+		if err != nil {
+			fmt.Fprintf(w, "%s: error: %v\n", path, err)
+			status = 1
+			continue
+		}
+
+		broken := map[int]bool{}
+		for _, d := range diags {
+			fmt.Fprintf(w, "%s: error: %v [%s]\n", path, d, d.Kind)
+			broken[d.Item] = true
+			status = 1
+		}
+		for _, f := range envelope.Validate(env) {
+			// Already reported as a parse diagnostic
+			if f.Rule == "invalid-header" && broken[f.Item] {
+				continue
+			}
+			fmt.Fprintf(w, "%s: %s\n", path, f)
+			if f.Severity == envelope.SeverityError {
+				status = 1
+			}
+		}
+	}
+	return status
+}
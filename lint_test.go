@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.envelope")
+	broken := filepath.Join(dir, "broken.envelope")
+	session := `{"sid":"e2b6cc4c-8a71-4a4c-b1d9-5ba1db7a0a53","attrs":{"release":"app@1.0"}}`
+	os.WriteFile(valid, []byte("{}\n{\"type\":\"session\"}\n"+session+"\n"), 0o644)
+	os.WriteFile(broken, []byte("{}\nnot json\n{\"type\":\"attachment\"}\nhello\n"), 0o644)
+
+	var out bytes.Buffer
+	if status := lint(&out, []string{valid}); status != 0 {
+		t.Errorf("valid: status = %d, want 0, output:\n%s", status, out.String())
+	}
+
+	out.Reset()
+	if status := lint(&out, []string{broken, filepath.Join(dir, "missing.envelope")}); status != 1 {
+		t.Errorf("broken: status = %d, want 1", status)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("output lines = %d, want 3:\n%s", len(lines), out.String())
+	}
+	for i, want := range []string{"[invalid_header]", "[missing-filename]", "missing.envelope"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
@@ -9,36 +10,93 @@ import (
 	"github.com/getsentry/slope/tui"
 )
 
+const usage = "usage: slope [--debug-dir dir]... [--mapping file]... [--source-maps [prefix=]dir]... <file.envelope>\n" +
+	"       slope lint <file.envelope>...\n" +
+	"       slope client-reports <file.envelope|dir>...\n" +
+	"       slope symbolicate [--debug-dir dir]... [--mapping file]... [--source-maps [prefix=]dir]... [-o out.envelope] <file.envelope>\n" +
+	"       slope debug-images [--debug-dir dir]... [--mapping file]... [--source-maps [prefix=]dir]... <file.envelope>...\n" +
+	"       slope source-context --root dir [--rewrite from=to]... [-o out.envelope] <file.envelope>\n"
+
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintf(os.Stderr, "usage: slope <file.envelope>\n")
-		os.Exit(1)
+	if len(os.Args) >= 2 && os.Args[1] == "lint" {
+		if len(os.Args) == 2 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(1)
+		}
+		os.Exit(lint(os.Stdout, os.Args[2:]))
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "client-reports" {
+		if len(os.Args) == 2 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(1)
+		}
+		os.Exit(clientReports(os.Stdout, os.Args[2:]))
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "symbolicate" {
+		os.Exit(symbolicateEnvelope(os.Stderr, os.Args[2:]))
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "debug-images" {
+		os.Exit(debugImages(os.Stdout, os.Args[2:]))
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "source-context" {
+		os.Exit(sourceContext(os.Stderr, os.Args[2:]))
 	}
 
-	path := os.Args[1]
-	f, err := os.Open(path)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	fs := flag.NewFlagSet("slope", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	s := symbolicatorFlags(fs)
+	if err := fs.Parse(os.Args[1:]); err != nil {
 		os.Exit(1)
 	}
-	defer f.Close()
-
-	fi, err := f.Stat()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	if fs.NArg() != 1 {
+		fs.Usage()
 		os.Exit(1)
 	}
 
-	env, err := envelope.Parse(f)
+	path := fs.Arg(0)
+	env, diags, size, err := readEnvelope(path)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 
-	m := tui.NewModel(env, path, fi.Size())
+	m := tui.NewModel(env, path, size)
+	m.SetDiagnostics(diags)
+	m.SetSymbolicator(s)
 	p := tea.NewProgram(m)
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+func readEnvelope(path string) (*envelope.Envelope, []*envelope.ParseError, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	env, diags, err := envelope.ParseLenient(f)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return env, diags, fi.Size(), nil
+}
+
+// readIntactEnvelope reads an envelope to write back. Unlike readEnvelope,
+// it fails on damage that lenient parsing recovers from, so that a partly
+// recovered envelope does not replace the original.
+func readIntactEnvelope(path string) (*envelope.Envelope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return envelope.Parse(f)
+}
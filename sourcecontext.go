@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/getsentry/slope/symbolicate"
+)
+
+// sourceContext fills in the source context of the frames of the events in
+// an envelope from the checkout given with --root, with the paths in frames
+// rewritten by the rules given with --rewrite, and writes the envelope back,
+// or to the file given with -o. It returns the exit status: 1 on errors or
+// if no context was added, 0 otherwise.
+func sourceContext(w io.Writer, args []string) int {
+	fs := flag.NewFlagSet("source-context", flag.ContinueOnError)
+	fs.SetOutput(w)
+	fs.Usage = func() { fmt.Fprint(w, usage) }
+	var c symbolicate.SourceContext
+	fs.StringVar(&c.Root, "root", "", "source checkout `dir` that relative paths are resolved in")
+	fs.Var((*stringsFlag)(&c.Rewrites), "rewrite", "path prefix rewrite `from=to` (repeatable)")
+	output := fs.String("o", "", "write to `file` instead of in place")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() != 1 || c.Root == "" {
+		fs.Usage()
+		return 1
+	}
+
+	path := fs.Arg(0)
+	env, err := readIntactEnvelope(path)
+	if err != nil {
+		fmt.Fprintf(w, "%s: error: %v\n", path, err)
+		return 1
+	}
+
+	var frames, filled int
+	for i := range env.Items {
+		item := &env.Items[i]
+		if item.Type != "event" && item.Type != "transaction" {
+			continue
+		}
+		ev, err := item.Event()
+		if err != nil {
+			fmt.Fprintf(w, "%s: item %d: error: %v\n", path, i+1, err)
+			return 1
+		}
+		n, m := c.Apply(ev)
+		frames += n
+		filled += m
+		if m == 0 {
+			continue
+		}
+		if err := item.SetEvent(ev); err != nil {
+			fmt.Fprintf(w, "%s: item %d: error: %v\n", path, i+1, err)
+			return 1
+		}
+	}
+	fmt.Fprintf(w, "added context to %d of %d frames\n", filled, frames)
+	if filled == 0 {
+		return 1
+	}
+
+	if *output == "" {
+		*output = path
+	}
+	if err := writeEnvelope(env, *output); err != nil {
+		fmt.Fprintf(w, "%s: error: %v\n", *output, err)
+		return 1
+	}
+	return 0
+}
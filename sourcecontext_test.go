@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getsentry/slope/envelope"
+)
+
+func TestSourceContext(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "crash.envelope")
+	out := filepath.Join(dir, "out.envelope")
+	ev := `{"exception":{"values":[{"stacktrace":{"frames":[` +
+		`{"function":"main","abs_path":"/build/crash.c","lineno":22},` +
+		`{"function":"run","abs_path":"/build/missing.c","lineno":17}]}}]}}`
+	data := fmt.Sprintf("{}\n{\"type\":\"event\",\"length\":%d}\n%s\n", len(ev), ev)
+	os.WriteFile(path, []byte(data), 0o644)
+
+	var w bytes.Buffer
+	if status := sourceContext(&w, []string{path}); status != 1 {
+		t.Errorf("without --root: status = %d, want 1", status)
+	}
+
+	w.Reset()
+	if status := sourceContext(&w, []string{"--root", "symbolicate/testdata", path}); status != 1 {
+		t.Errorf("without --rewrite: status = %d, want 1", status)
+	}
+	if got := strings.TrimSpace(w.String()); got != "added context to 0 of 2 frames" {
+		t.Errorf("without --rewrite: output = %q", got)
+	}
+
+	w.Reset()
+	status := sourceContext(&w, []string{"--root", "symbolicate/testdata", "--rewrite", "/build/=", "-o", out, path})
+	if status != 0 {
+		t.Fatalf("status = %d, want 0, output:\n%s", status, w.String())
+	}
+	if got := strings.TrimSpace(w.String()); got != "added context to 1 of 2 frames" {
+		t.Errorf("output = %q", got)
+	}
+	if unchanged, _ := os.ReadFile(path); string(unchanged) != data {
+		t.Errorf("input file was modified")
+	}
+
+	env, _, _, err := readEnvelope(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	withContext, err := env.Items[0].Event()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f := withContext.Exception[0].Stacktrace.Frames[0]; f.ContextLine != "    run(argc);" || len(f.PreContext) != 5 {
+		t.Errorf("frame context = %q %q", f.PreContext, f.ContextLine)
+	}
+	if findings := envelope.Validate(env); len(findings) != 0 {
+		t.Errorf("findings = %v", findings)
+	}
+}
+
+func TestSourceContextDamaged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "damaged.envelope")
+	data := "{}\n{\"type\":\"event\",\"length\":100}\n{}\n"
+	os.WriteFile(path, []byte(data), 0o644)
+
+	var w bytes.Buffer
+	if status := sourceContext(&w, []string{"--root", "symbolicate/testdata", path}); status != 1 {
+		t.Errorf("status = %d, want 1", status)
+	}
+	if !strings.Contains(w.String(), "error:") {
+		t.Errorf("output = %q", w.String())
+	}
+	if unchanged, _ := os.ReadFile(path); string(unchanged) != data {
+		t.Errorf("damaged file was written back:\n%s", unchanged)
+	}
+}
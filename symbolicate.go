@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/getsentry/slope/envelope"
+	"github.com/getsentry/slope/envelope/event"
+	"github.com/getsentry/slope/symbolicate"
+)
+
+// stringsFlag is a flag that may be given more than once.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// symbolicatorFlags registers the --debug-dir, --mapping and --source-maps
+// flags, which configure the returned symbolicator.
+func symbolicatorFlags(fs *flag.FlagSet) *symbolicate.Symbolicator {
+	s := &symbolicate.Symbolicator{}
+	fs.Var((*stringsFlag)(&s.DebugDirs), "debug-dir", "directory to search for debug files (repeatable)")
+	fs.Var((*stringsFlag)(&s.Mappings), "mapping", "ProGuard or R8 mapping `file`, or directory of them (repeatable)")
+	fs.Var((*stringsFlag)(&s.SourceMaps), "source-maps", "`[url-prefix=]dir` of source maps and minified files (repeatable)")
+	return s
+}
+
+// symbolicateEnvelope symbolicates the events of an envelope with the debug
+// files found in the directories given with --debug-dir, the mapping files
+// given with --mapping and the source maps given with --source-maps, and
+// writes the envelope back, or to the file given with -o. It returns the
+// exit status: 1 on errors or if nothing was symbolicated, 0 otherwise.
+func symbolicateEnvelope(w io.Writer, args []string) int {
+	fs := flag.NewFlagSet("symbolicate", flag.ContinueOnError)
+	fs.SetOutput(w)
+	fs.Usage = func() { fmt.Fprint(w, usage) }
+	s := symbolicatorFlags(fs)
+	output := fs.String("o", "", "write to `file` instead of in place")
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() != 1 || !s.Enabled() {
+		fs.Usage()
+		return 1
+	}
+
+	path := fs.Arg(0)
+	env, err := readIntactEnvelope(path)
+	if err != nil {
+		fmt.Fprintf(w, "%s: error: %v\n", path, err)
+		return 1
+	}
+
+	var total symbolicate.Stats
+	for i := range env.Items {
+		item := &env.Items[i]
+		if item.Type != "event" && item.Type != "transaction" {
+			continue
+		}
+		ev, err := item.Event()
+		if err != nil {
+			fmt.Fprintf(w, "%s: item %d: error: %v\n", path, i+1, err)
+			return 1
+		}
+		stats, err := s.Symbolicate(ev)
+		if err != nil {
+			fmt.Fprintf(w, "%s: item %d: error: %v\n", path, i+1, err)
+			return 1
+		}
+		for _, img := range stats.Missing {
+			fmt.Fprintf(w, "%s: item %d: no debug file for %s\n", path, i+1, describeImage(img))
+		}
+		if err := item.SetEvent(ev); err != nil {
+			fmt.Fprintf(w, "%s: item %d: error: %v\n", path, i+1, err)
+			return 1
+		}
+		total.Frames += stats.Frames
+		total.Symbolicated += stats.Symbolicated
+	}
+	fmt.Fprintf(w, "symbolicated %d of %d frames\n", total.Symbolicated, total.Frames)
+	if total.Symbolicated == 0 {
+		return 1
+	}
+
+	if *output == "" {
+		*output = path
+	}
+	if err := writeEnvelope(env, *output); err != nil {
+		fmt.Fprintf(w, "%s: error: %v\n", *output, err)
+		return 1
+	}
+	return 0
+}
+
+// writeEnvelope writes an envelope through a temporary file, so that a
+// failed write leaves an existing file intact.
+func writeEnvelope(env *envelope.Envelope, path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".slope-*")
+	if err != nil {
+		return err
+	}
+	if err := env.SerializeLossless(tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// describeImage names an image by its code file and ID, or its UUID for
+// proguard images.
+func describeImage(img event.DebugImage) string {
+	switch {
+	case img.Type == "proguard":
+		return "proguard " + img.UUID
+	case img.CodeID != "":
+		return fmt.Sprintf("%s (%s)", img.CodeFile, img.CodeID)
+	}
+	return fmt.Sprintf("%s (%s)", img.CodeFile, img.DebugID)
+}
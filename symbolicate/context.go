@@ -0,0 +1,139 @@
+package symbolicate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// contextLines is the number of source lines around a frame's line that
+// go into its pre and post context, as Sentry does.
+const contextLines = 5
+
+// SourceContext fills in the source lines around frames from the files of a
+// local checkout. Paths in frames are rewritten by the first rule whose
+// prefix they have, and relative paths, rewritten or not, are taken relative
+// to the root. Only files under the root are read, after symlinks are
+// resolved. Files are read once and cached.
+type SourceContext struct {
+	Root     string
+	Rewrites []string // path prefix rewrites, as from=to
+
+	files map[string][]string // lines by path, nil if not readable
+}
+
+// Apply fills in the context of the frames of an event's exceptions and
+// threads that have a line number but no context line, if their file is
+// found. It returns the number of such frames and how many got context.
+func (c *SourceContext) Apply(ev *event.Event) (frames, filled int) {
+	for _, st := range stacktraces(ev) {
+		for i := range st.Frames {
+			f := &st.Frames[i]
+			if f.Lineno == 0 || f.ContextLine != "" || f.AbsPath == "" && f.Filename == "" {
+				continue
+			}
+			frames++
+			for _, p := range []string{f.AbsPath, f.Filename} {
+				if p == "" {
+					continue
+				}
+				local, ok := c.localPath(p)
+				if !ok {
+					continue
+				}
+				pre, current, post, ok := contextAround(c.lines(local), f.Lineno-1)
+				if ok {
+					f.PreContext, f.ContextLine, f.PostContext = pre, current, post
+					filled++
+					break
+				}
+			}
+		}
+	}
+	return frames, filled
+}
+
+// localPath rewrites the path of a frame to a local one, and reports
+// whether it is under the root. Frame paths come from the event, so they
+// must not reach other files, such as with .. or a symlink.
+func (c *SourceContext) localPath(p string) (string, bool) {
+	for _, rule := range c.Rewrites {
+		if from, to := splitRule(rule); from != "" && strings.HasPrefix(p, from) {
+			p = to + p[len(from):]
+			break
+		}
+	}
+	p = filepath.FromSlash(p)
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(c.Root, p)
+	}
+	root, err := resolvePath(c.Root)
+	if err != nil {
+		return "", false
+	}
+	resolved, err := resolvePath(p)
+	if err != nil {
+		return "", false
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return p, true
+}
+
+// resolvePath returns the absolute path of an existing file with symlinks
+// resolved.
+func resolvePath(p string) (string, error) {
+	p, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(p)
+}
+
+func (c *SourceContext) lines(path string) []string {
+	if lines, ok := c.files[path]; ok {
+		return lines
+	}
+	var lines []string
+	if data, err := os.ReadFile(path); err == nil {
+		lines = splitLines(string(data))
+	}
+	if c.files == nil {
+		c.files = map[string][]string{}
+	}
+	c.files[path] = lines
+	return lines
+}
+
+// contextAround returns the lines around a zero-based line.
+func contextAround(lines []string, line int) (pre []string, current string, post []string, ok bool) {
+	if line < 0 || line >= len(lines) {
+		return nil, "", nil, false
+	}
+	pre = lines[max(line-contextLines, 0):line]
+	post = lines[line+1 : min(line+1+contextLines, len(lines))]
+	return pre, lines[line], post, true
+}
+
+// splitLines splits source code into lines, without line endings.
+func splitLines(s string) []string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	return lines
+}
+
+// splitRule splits a rule given as prefix=value, such as a path rewrite or
+// a source map directory with its URL prefix. Without a separator, the
+// prefix is empty.
+func splitRule(rule string) (prefix, value string) {
+	i := strings.LastIndexByte(rule, '=')
+	if i < 0 {
+		return "", rule
+	}
+	return rule[:i], rule[i+1:]
+}
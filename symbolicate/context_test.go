@@ -0,0 +1,96 @@
+package symbolicate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+func TestSourceContext(t *testing.T) {
+	ev, err := event.Parse([]byte(`{"exception":{"values":[{"stacktrace":{"frames":[` +
+		`{"function":"main","abs_path":"/home/runner/work/app/crash.c","filename":"crash.c","lineno":22},` +
+		`{"function":"run","filename":"crash.c","lineno":17},` +
+		`{"function":"write_value","abs_path":"/elsewhere/crash.c","lineno":7},` +
+		`{"function":"past_end","filename":"crash.c","lineno":100},` +
+		`{"function":"known","filename":"crash.c","lineno":1,"context_line":"kept"},` +
+		`{"function":"libc","lineno":1}]}}]}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := &SourceContext{Root: "testdata", Rewrites: []string{"/home/runner/work/app/=", "/elsewhere/=./"}}
+	frames, filled := c.Apply(ev)
+	if frames != 4 || filled != 3 {
+		t.Errorf("Apply = %d, %d, want 4, 3", frames, filled)
+	}
+
+	fs := ev.Exception[0].Stacktrace.Frames
+	if f := fs[0]; f.ContextLine != "    run(argc);" || len(f.PreContext) != 5 || f.PreContext[4] != "{" || len(f.PostContext) != 2 || f.PostContext[1] != "}" {
+		t.Errorf("main: %q %q %q", f.PreContext, f.ContextLine, f.PostContext)
+	}
+	if f := fs[1]; f.ContextLine != "    trigger_crash(value);" {
+		t.Errorf("run: context line = %q", f.ContextLine)
+	}
+	if f := fs[2]; f.ContextLine != "    *target = value;" {
+		t.Errorf("write_value: context line = %q", f.ContextLine)
+	}
+	if f := fs[3]; f.ContextLine != "" || f.PreContext != nil {
+		t.Errorf("past the end: %q %q", f.PreContext, f.ContextLine)
+	}
+	if f := fs[4]; f.ContextLine != "kept" || f.PreContext != nil {
+		t.Errorf("existing context: %q %q", f.PreContext, f.ContextLine)
+	}
+}
+
+func TestSourceContextMissing(t *testing.T) {
+	ev, err := event.Parse([]byte(`{"exception":{"values":[{"stacktrace":{"frames":[{"filename":"missing.c","lineno":1}]}}]}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if frames, filled := (&SourceContext{Root: "testdata"}).Apply(ev); frames != 1 || filled != 0 {
+		t.Errorf("Apply = %d, %d, want 1, 0", frames, filled)
+	}
+}
+
+func TestSourceContextOutsideRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "root")
+	os.MkdirAll(root, 0o755)
+	os.WriteFile(filepath.Join(root, "app.c"), []byte("app\n"), 0o644)
+	secret := filepath.Join(dir, "secret")
+	os.WriteFile(secret, []byte("secret\n"), 0o644)
+	if err := os.Symlink(secret, filepath.Join(root, "link.c")); err != nil {
+		t.Fatal(err)
+	}
+
+	frame := func(p string) string {
+		data, _ := json.Marshal(filepath.ToSlash(p))
+		return `{"abs_path":` + string(data) + `,"lineno":1}`
+	}
+	ev, err := event.Parse([]byte(`{"exception":{"values":[{"stacktrace":{"frames":[` +
+		frame(secret) + "," +
+		frame("../secret") + "," +
+		frame("/build/../secret") + "," + // rewritten out of the root
+		frame("link.c") + "," + // a symlink out of the root
+		frame(filepath.Join(root, "app.c")) + "," + // absolute, in the root
+		frame("/ci/app.c") + // rewritten to an absolute path in the root
+		`]}}]}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := &SourceContext{Root: root, Rewrites: []string{"/build/=", "/ci/=" + filepath.ToSlash(root) + "/"}}
+	if frames, filled := c.Apply(ev); frames != 6 || filled != 2 {
+		t.Errorf("Apply = %d, %d, want 6, 2", frames, filled)
+	}
+	for i, f := range ev.Exception[0].Stacktrace.Frames {
+		want := ""
+		if i >= 4 {
+			want = "app"
+		}
+		if f.ContextLine != want {
+			t.Errorf("frame %d: context line = %q, want %q", i, f.ContextLine, want)
+		}
+	}
+}
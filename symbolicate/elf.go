@@ -0,0 +1,350 @@
+package symbolicate
+
+import (
+	"bytes"
+	"cmp"
+	"debug/dwarf"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// debugFile is an ELF file with the debug information to resolve addresses
+// of one image.
+type debugFile struct {
+	path    string
+	buildID []byte
+	vmaddr  uint64 // address that the image is linked at
+	dwarf   *dwarf.Data
+	units   []unit
+	symbols []elf.Symbol // functions, sorted by address
+}
+
+// unit is a compilation unit and the address ranges of its code.
+type unit struct {
+	entry  *dwarf.Entry
+	ranges [][2]uint64
+}
+
+// location is the function and source position of an address, one per
+// inlined call.
+type location struct {
+	function   string
+	linkage    string
+	file       string
+	line, col  int
+	symbolAddr uint64
+}
+
+func openDebugFile(name string) (*debugFile, error) {
+	f, err := elf.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	df := &debugFile{path: name, buildID: buildID(f)}
+	for _, p := range f.Progs {
+		if p.Type == elf.PT_LOAD {
+			df.vmaddr = p.Vaddr &^ (max(p.Align, 1) - 1)
+			break
+		}
+	}
+
+	if d, err := f.DWARF(); err == nil {
+		df.dwarf = d
+		if df.units, err = compileUnits(d); err != nil {
+			return nil, fmt.Errorf("reading DWARF of %s: %w", name, err)
+		}
+	}
+	for _, load := range []func() ([]elf.Symbol, error){f.Symbols, f.DynamicSymbols} {
+		symbols, _ := load()
+		for _, s := range symbols {
+			if elf.ST_TYPE(s.Info) == elf.STT_FUNC && s.Value != 0 {
+				df.symbols = append(df.symbols, s)
+			}
+		}
+	}
+	slices.SortFunc(df.symbols, func(a, b elf.Symbol) int {
+		return cmp.Compare(a.Value, b.Value)
+	})
+	return df, nil
+}
+
+// buildID returns the GNU build ID note of an ELF file, or nil.
+func buildID(f *elf.File) []byte {
+	for _, s := range f.Sections {
+		if s.Type != elf.SHT_NOTE {
+			continue
+		}
+		data, err := s.Data()
+		if err != nil {
+			continue
+		}
+		if id := noteBuildID(data, f.ByteOrder); id != nil {
+			return id
+		}
+	}
+	return nil
+}
+
+// noteBuildID returns the GNU build ID in the notes of a section, or nil.
+// Notes are a name size, a descriptor size and a type, followed by the name
+// and the descriptor, each padded to 4 bytes. Offsets are computed in 64
+// bits so that sizes near the 32-bit limit cannot wrap around.
+func noteBuildID(data []byte, order binary.ByteOrder) []byte {
+	for len(data) >= 12 {
+		namesz := uint64(order.Uint32(data))
+		descsz := uint64(order.Uint32(data[4:]))
+		typ := order.Uint32(data[8:])
+		nameEnd := 12 + align4(namesz)
+		descEnd := nameEnd + align4(descsz)
+		if descEnd > uint64(len(data)) {
+			break
+		}
+		if typ == 3 && bytes.Equal(data[12:12+namesz], []byte("GNU\x00")) { // NT_GNU_BUILD_ID
+			return data[nameEnd : nameEnd+descsz]
+		}
+		data = data[descEnd:]
+	}
+	return nil
+}
+
+func align4(n uint64) uint64 {
+	return (n + 3) &^ 3
+}
+
+func compileUnits(d *dwarf.Data) ([]unit, error) {
+	var units []unit
+	r := d.Reader()
+	for {
+		e, err := r.Next()
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			return units, nil
+		}
+		if e.Tag == dwarf.TagCompileUnit || e.Tag == dwarf.TagPartialUnit {
+			ranges, err := d.Ranges(e)
+			if err != nil {
+				return nil, err
+			}
+			units = append(units, unit{e, ranges})
+		}
+		r.SkipChildren()
+	}
+}
+
+func containsPC(ranges [][2]uint64, pc uint64) bool {
+	for _, r := range ranges {
+		if pc >= r[0] && pc < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup resolves a linked address to its locations, innermost inlined
+// call first. It returns nil if the address is not covered.
+func (df *debugFile) lookup(pc uint64) ([]location, error) {
+	if df.dwarf != nil {
+		for _, u := range df.units {
+			if containsPC(u.ranges, pc) {
+				return df.lookupDWARF(u.entry, pc)
+			}
+		}
+	}
+	if s := df.symbol(pc); s != nil {
+		return []location{{function: s.Name, linkage: s.Name, symbolAddr: s.Value}}, nil
+	}
+	return nil, nil
+}
+
+func (df *debugFile) symbol(pc uint64) *elf.Symbol {
+	i, _ := slices.BinarySearchFunc(df.symbols, pc+1, func(s elf.Symbol, pc uint64) int {
+		if s.Value < pc {
+			return -1
+		}
+		return 1
+	})
+	if i == 0 {
+		return nil
+	}
+	s := &df.symbols[i-1]
+	if s.Size > 0 && pc-s.Value >= s.Size {
+		return nil
+	}
+	return s
+}
+
+func (df *debugFile) lookupDWARF(cu *dwarf.Entry, pc uint64) ([]location, error) {
+	scopes, err := df.scopes(cu, pc)
+	if err != nil {
+		return nil, err
+	}
+	lr, err := df.dwarf.LineReader(cu)
+	if err != nil {
+		return nil, err
+	}
+
+	// The line table gives the position in the innermost scope, and each
+	// inlined call gives the position in the scope around it
+	var loc location
+	var files []*dwarf.LineFile
+	if lr != nil {
+		if entry, err := seekPC(lr, pc); err != nil {
+			return nil, err
+		} else if entry != nil {
+			loc.file = entry.File.Name
+			loc.line = entry.Line
+			loc.col = entry.Column
+		}
+		files = lr.Files()
+	}
+	if len(scopes) == 0 {
+		if s := df.symbol(pc); s != nil {
+			loc.function, loc.linkage, loc.symbolAddr = s.Name, s.Name, s.Value
+		}
+		return []location{loc}, nil
+	}
+
+	var symbolAddr uint64
+	if ranges, _ := df.dwarf.Ranges(scopes[0]); len(ranges) > 0 {
+		symbolAddr = ranges[0][0]
+	}
+	var locations []location
+	for i := len(scopes) - 1; i >= 0; i-- {
+		loc.function, loc.linkage = df.names(scopes[i])
+		loc.symbolAddr = symbolAddr
+		locations = append(locations, loc)
+
+		call := scopes[i]
+		loc = location{}
+		if n, ok := call.Val(dwarf.AttrCallFile).(int64); ok && n >= 0 && int(n) < len(files) && files[n] != nil {
+			loc.file = files[n].Name
+		}
+		if n, ok := call.Val(dwarf.AttrCallLine).(int64); ok {
+			loc.line = int(n)
+		}
+		if n, ok := call.Val(dwarf.AttrCallColumn).(int64); ok {
+			loc.col = int(n)
+		}
+	}
+	return locations, nil
+}
+
+// seekPC returns the last line table row at or before pc within the
+// sequence that covers pc, or nil. Unlike LineReader.SeekPC, it does not
+// assume that the sequences are sorted by address, which they are not when
+// functions are placed in separate sections.
+func seekPC(lr *dwarf.LineReader, pc uint64) (*dwarf.LineEntry, error) {
+	var found, prev *dwarf.LineEntry
+	for {
+		var entry dwarf.LineEntry
+		if err := lr.Next(&entry); err == io.EOF {
+			return found, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if prev != nil && prev.Address <= pc && pc < entry.Address {
+			found = prev
+		}
+		prev = &entry
+		if entry.EndSequence {
+			prev = nil
+		}
+	}
+}
+
+// scopes returns the function containing pc and the inlined calls down to
+// pc, outermost first.
+func (df *debugFile) scopes(cu *dwarf.Entry, pc uint64) ([]*dwarf.Entry, error) {
+	if !cu.Children {
+		return nil, nil
+	}
+	r := df.dwarf.Reader()
+	r.Seek(cu.Offset)
+	if _, err := r.Next(); err != nil {
+		return nil, err
+	}
+
+	var scopes []*dwarf.Entry
+	for depth := 1; depth > 0; {
+		e, err := r.Next()
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			break
+		}
+		if e.Tag == 0 {
+			depth--
+			continue
+		}
+		switch e.Tag {
+		case dwarf.TagSubprogram, dwarf.TagInlinedSubroutine, dwarf.TagLexDwarfBlock:
+			ranges, err := df.dwarf.Ranges(e)
+			if err != nil {
+				return nil, err
+			}
+			if containsPC(ranges, pc) {
+				if e.Tag != dwarf.TagLexDwarfBlock {
+					scopes = append(scopes, e)
+				}
+				if e.Children {
+					depth++
+				}
+				continue
+			}
+		case dwarf.TagNamespace, dwarf.TagClassType, dwarf.TagStructType, dwarf.TagUnionType:
+			// C++ functions may be defined within their namespace or class
+			if e.Children {
+				depth++
+			}
+			continue
+		}
+		if e.Children {
+			r.SkipChildren()
+		}
+	}
+	return scopes, nil
+}
+
+// names returns the name and the linkage name of a function, following
+// references to its abstract origin or declaration.
+func (df *debugFile) names(e *dwarf.Entry) (name, linkage string) {
+	for range 8 {
+		if name == "" {
+			name, _ = e.Val(dwarf.AttrName).(string)
+		}
+		if linkage == "" {
+			linkage, _ = e.Val(dwarf.AttrLinkageName).(string)
+		}
+		if name != "" && linkage != "" {
+			break
+		}
+		ref, ok := e.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)
+		if !ok {
+			if ref, ok = e.Val(dwarf.AttrSpecification).(dwarf.Offset); !ok {
+				break
+			}
+		}
+		r := df.dwarf.Reader()
+		r.Seek(ref)
+		next, err := r.Next()
+		if err != nil || next == nil {
+			break
+		}
+		e = next
+	}
+	return name, linkage
+}
+
+// base returns the file name of a source path, which may be a Windows path.
+func base(path string) string {
+	return path[strings.LastIndexAny(path, `/\`)+1:]
+}
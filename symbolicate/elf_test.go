@@ -0,0 +1,29 @@
+package symbolicate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestNoteBuildID(t *testing.T) {
+	note := func(namesz, descsz, typ uint32, rest string) []byte {
+		data := binary.LittleEndian.AppendUint32(nil, namesz)
+		data = binary.LittleEndian.AppendUint32(data, descsz)
+		data = binary.LittleEndian.AppendUint32(data, typ)
+		return append(data, rest...)
+	}
+	data := append(note(4, 2, 1, "GNU\x00ab\x00\x00"), note(4, 3, 3, "GNU\x00xyz\x00")...)
+	if got := noteBuildID(data, binary.LittleEndian); !bytes.Equal(got, []byte("xyz")) {
+		t.Errorf("build ID = %q", got)
+	}
+	for name, data := range map[string][]byte{
+		"name size wraps":       note(0xfffffffd, 0, 3, "GNU\x00"),
+		"descriptor size wraps": note(4, 0xfffffffd, 3, "GNU\x00xyz\x00"),
+		"truncated":             note(4, 8, 3, "GNU\x00xyz\x00"),
+	} {
+		if got := noteBuildID(data, binary.LittleEndian); got != nil {
+			t.Errorf("%s: build ID = %q", name, got)
+		}
+	}
+}
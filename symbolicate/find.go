@@ -0,0 +1,193 @@
+package symbolicate
+
+import (
+	"debug/elf"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// Locate returns the path of the debug file of an image in the debug
+// directories, of the mapping file of a proguard image, or of the source map
+// of a sourcemap image, or an empty string if there is none. The directories
+// may be laid out by build ID as GDB and debuginfod do, as symbol servers do,
+// or hold .debug directories and files named after the image. ELF files
+// found by name must have the build ID of the image. Failing all layouts,
+// ELF files anywhere in the directories are matched by build ID.
+func (s *Symbolicator) Locate(img event.DebugImage) (string, error) {
+	if img.Type == "proguard" {
+		if img.UUID == "" {
+			return "", nil
+		}
+		return s.locateMapping(img.UUID)
+	}
+	if img.Type == "sourcemap" {
+		if img.DebugID == "" || len(s.SourceMaps) == 0 {
+			return "", nil
+		}
+		mapPath, minifiedPath, err := s.locateSourceMap(img.DebugID)
+		if err != nil || mapPath != "" || minifiedPath == "" {
+			return mapPath, err
+		}
+		// A minified file with the debug ID, whose source map is inlined or
+		// next to it
+		if f, err := s.minified(img.CodeFile, img.DebugID); err != nil || f == nil {
+			return "", err
+		}
+		return minifiedPath, nil
+	}
+	id := imageBuildID(img)
+	for _, dir := range s.DebugDirs {
+		for _, p := range candidates(dir, img, id) {
+			if fi, err := os.Stat(p); err != nil || !fi.Mode().IsRegular() {
+				continue
+			}
+			if id == "" || strings.HasPrefix(fileBuildID(p), id) {
+				return p, nil
+			}
+		}
+	}
+	if id == "" {
+		return "", nil
+	}
+
+	if s.index == nil {
+		if err := s.indexDirs(); err != nil {
+			return "", err
+		}
+	}
+	if p, ok := s.index[id]; ok {
+		return p, nil
+	}
+	for buildID, p := range s.index {
+		if strings.HasPrefix(buildID, id) {
+			return p, nil
+		}
+	}
+	return "", nil
+}
+
+// candidates returns the paths in a debug directory that may hold the
+// debug file of an image, given its build ID, if any.
+func candidates(dir string, img event.DebugImage, id string) []string {
+	var paths []string
+	add := func(elem ...string) {
+		paths = append(paths, filepath.Join(append([]string{dir}, elem...)...))
+	}
+	name := base(img.CodeFile)
+	debugName := base(img.DebugFile)
+	if debugName == "" {
+		debugName = name
+	}
+
+	if len(id) > 2 {
+		// GDB, and the same without the .build-id directory
+		add(".build-id", id[:2], id[2:]+".debug")
+		add(id[:2], id[2:]+".debug")
+		// Sentry's unified symbol server layout
+		add(id[:2], id[2:], "debuginfo")
+		add(id[:2], id[2:], "executable")
+		// The debuginfod client cache
+		add(id, "debuginfo")
+		add(id, "executable")
+		// SSQP, as served by Microsoft's and Sentry's symbol servers
+		add("_.debug", "elf-buildid-sym-"+id, "_.debug")
+		if name != "" {
+			add(strings.ToLower(name), "elf-buildid-"+id, strings.ToLower(name))
+		}
+		// .debug directories next to the image, and files named after it
+		if name != "" {
+			add(".debug", name+".debug")
+			add(".debug", name)
+			add(name + ".debug")
+			add(name)
+		}
+	}
+
+	// Windows symbol stores, by PDB signature and age or by PE timestamp
+	// and size
+	if sig := pdbSignature(img.DebugID); sig != "" && debugName != "" {
+		add(debugName, sig, debugName)
+	}
+	if sig := peSignature(img.CodeID); sig != "" && id == "" && name != "" {
+		add(name, sig, name)
+	}
+	return paths
+}
+
+// pdbSignature returns the directory name that symbol stores use for a
+// PDB: the GUID of the debug ID in upper case hex without dashes, followed
+// by the age in hex.
+func pdbSignature(debugID string) string {
+	if len(debugID) < 36 {
+		return ""
+	}
+	guid := strings.ReplaceAll(debugID[:36], "-", "")
+	if _, err := hex.DecodeString(guid); err != nil || len(guid) != 32 {
+		return ""
+	}
+	age := "0"
+	if rest, ok := strings.CutPrefix(debugID[36:], "-"); ok {
+		if age = strings.TrimLeft(rest, "0"); age == "" {
+			age = "0"
+		}
+	}
+	return strings.ToUpper(guid + age)
+}
+
+// peSignature returns the directory name that symbol stores use for a PE
+// file: its timestamp in upper case hex followed by its size, which is
+// how the code ID is made up.
+func peSignature(codeID string) string {
+	if len(codeID) <= 8 {
+		return ""
+	}
+	return strings.ToUpper(codeID[:8]) + strings.ToLower(codeID[8:])
+}
+
+// indexDirs walks the debug directories for ELF files and records their
+// build IDs. Entries that cannot be read are skipped, so that they do not
+// fail every lookup, but the directories themselves must exist.
+func (s *Symbolicator) indexDirs() error {
+	s.index = map[string]string{}
+	for _, dir := range s.DebugDirs {
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if p == dir {
+					return err
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			id := fileBuildID(p)
+			if id == "" {
+				return nil
+			}
+			if _, ok := s.index[id]; !ok {
+				s.index[id] = p
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileBuildID returns the build ID of an ELF file in hex, or an empty
+// string if the file is not an ELF file or has no build ID.
+func fileBuildID(name string) string {
+	f, err := elf.Open(name)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	return hex.EncodeToString(buildID(f))
+}
@@ -0,0 +1,113 @@
+package symbolicate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+const crashBuildID = "89a79abce0b8c4c8e628e26bc0b8e10f38a90b1a"
+
+func TestLocate(t *testing.T) {
+	crash, err := os.ReadFile("testdata/crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	elfImage := event.DebugImage{Type: "elf", CodeFile: "/usr/bin/crash", CodeID: crashBuildID}
+	peImage := event.DebugImage{Type: "pe", CodeFile: `C:\app\crash.exe`, CodeID: "5ab380779000",
+		DebugFile: `C:\build\crash.pdb`, DebugID: "3249d99d-0c40-4931-8610-f4e4fb0b6936-1a"}
+
+	tests := []struct {
+		name    string
+		img     event.DebugImage
+		path    string
+		indexed bool // found by walking the directory rather than by path
+	}{
+		{"gdb build-id", elfImage, ".build-id/89/a79abce0b8c4c8e628e26bc0b8e10f38a90b1a.debug", false},
+		{"build-id", elfImage, "89/a79abce0b8c4c8e628e26bc0b8e10f38a90b1a.debug", false},
+		{"unified", elfImage, "89/a79abce0b8c4c8e628e26bc0b8e10f38a90b1a/debuginfo", false},
+		{"debuginfod", elfImage, crashBuildID + "/debuginfo", false},
+		{"ssqp", elfImage, "_.debug/elf-buildid-sym-" + crashBuildID + "/_.debug", false},
+		{".debug dir", elfImage, ".debug/crash.debug", false},
+		{".debug file", elfImage, "crash.debug", false},
+		{"indexed", elfImage, "some/where/else", true},
+		{"debug id", event.DebugImage{Type: "elf", DebugID: "bc9aa789-b8e0-c8c4-e628-e26bc0b8e10f"}, "nested/crash", true},
+		{"pdb symstore", peImage, "crash.pdb/3249D99D0C4049318610F4E4FB0B69361A/crash.pdb", false},
+		{"pe symstore", peImage, "crash.exe/5AB380779000/crash.exe", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			p := filepath.Join(dir, filepath.FromSlash(tt.path))
+			os.MkdirAll(filepath.Dir(p), 0o755)
+			os.WriteFile(p, crash, 0o644)
+
+			s := &Symbolicator{DebugDirs: []string{dir}}
+			got, err := s.Locate(tt.img)
+			if err != nil || got != p {
+				t.Errorf("Locate = %q, %v, want %q", got, err, p)
+			}
+			if indexed := s.index != nil; indexed != tt.indexed {
+				t.Errorf("indexed = %v, want %v", indexed, tt.indexed)
+			}
+		})
+	}
+}
+
+func TestLocateUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions do not apply to root")
+	}
+	dir := t.TempDir()
+	crash, err := os.ReadFile("testdata/crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(dir, "a", "locked"), 0o755)
+	os.MkdirAll(filepath.Join(dir, "b"), 0o755)
+	os.WriteFile(filepath.Join(dir, "b", "crash"), crash, 0o644)
+	os.Chmod(filepath.Join(dir, "a", "locked"), 0)
+	t.Cleanup(func() { os.Chmod(filepath.Join(dir, "a", "locked"), 0o755) })
+
+	img := event.DebugImage{Type: "elf", CodeID: crashBuildID}
+	got, err := (&Symbolicator{DebugDirs: []string{dir}}).Locate(img)
+	if want := filepath.Join(dir, "b", "crash"); err != nil || got != want {
+		t.Errorf("Locate = %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestLocateMismatch(t *testing.T) {
+	dir := t.TempDir()
+	crash, err := os.ReadFile("testdata/crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Named after the image, but with another build ID
+	os.WriteFile(filepath.Join(dir, "crash.debug"), crash, 0o644)
+	img := event.DebugImage{Type: "elf", CodeFile: "/usr/bin/crash", CodeID: "00112233445566778899aabbccddeeff00112233"}
+
+	s := &Symbolicator{DebugDirs: []string{dir}}
+	if got, err := s.Locate(img); err != nil || got != "" {
+		t.Errorf("Locate = %q, %v, want none", got, err)
+	}
+	if _, err := (&Symbolicator{DebugDirs: []string{filepath.Join(dir, "missing")}}).Locate(img); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestPDBSignature(t *testing.T) {
+	tests := map[string]string{
+		"3249d99d-0c40-4931-8610-f4e4fb0b6936-1a": "3249D99D0C4049318610F4E4FB0B69361A",
+		"3249d99d-0c40-4931-8610-f4e4fb0b6936":    "3249D99D0C4049318610F4E4FB0B69360",
+		"3249d99d-0c40-4931-8610-f4e4fb0b6936-0":  "3249D99D0C4049318610F4E4FB0B69360",
+		"3249d99d":                                "",
+		"xxxxxxxx-0c40-4931-8610-f4e4fb0b6936":    "",
+	}
+	for id, want := range tests {
+		if got := pdbSignature(id); got != want {
+			t.Errorf("pdbSignature(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
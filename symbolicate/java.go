@@ -0,0 +1,224 @@
+package symbolicate
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// deobfuscate maps the obfuscated classes, methods and lines of Java frames
+// and exceptions back to the original ones, with the mapping files of the
+// event's proguard images. A frame of a method with inlined calls is
+// replaced by one frame per call.
+func (s *Symbolicator) deobfuscate(ev *event.Event, stats *Stats) error {
+	var maps []*mapping
+	for _, img := range ev.DebugMeta.Images {
+		if img.Type != "proguard" || img.UUID == "" {
+			continue
+		}
+		path, err := s.Locate(img)
+		if err != nil {
+			return err
+		}
+		if path == "" {
+			stats.Missing = append(stats.Missing, img)
+			continue
+		}
+		m, err := s.mapping(path)
+		if err != nil {
+			return err
+		}
+		maps = append(maps, m)
+	}
+	if len(maps) == 0 {
+		return nil
+	}
+
+	for i := range ev.Exception {
+		deobfuscateException(maps, &ev.Exception[i])
+	}
+	for _, st := range stacktraces(ev) {
+		var frames []event.Frame
+		for _, frame := range st.Frames {
+			if frame.Module == "" {
+				frames = append(frames, frame)
+				continue
+			}
+			stats.Frames++
+			var remapped []event.Frame
+			for _, m := range maps {
+				if remapped = m.remapFrame(frame); remapped != nil {
+					break
+				}
+			}
+			if remapped == nil {
+				frames = append(frames, frame)
+				continue
+			}
+			stats.Symbolicated++
+			frames = append(frames, remapped...)
+		}
+		st.Frames = frames
+	}
+	return nil
+}
+
+// deobfuscateException maps the class of an exception, which is split into
+// the package in module and the class name in type, or is all in type.
+func deobfuscateException(maps []*mapping, ex *event.Exception) {
+	name := ex.Type
+	if ex.Module != "" {
+		name = ex.Module + "." + ex.Type
+	}
+	for _, m := range maps {
+		orig := m.originalClass(name)
+		if orig == "" {
+			continue
+		}
+		if ex.Module == "" {
+			ex.Type = orig
+		} else if i := strings.LastIndexByte(orig, '.'); i >= 0 {
+			ex.Module, ex.Type = orig[:i], orig[i+1:]
+		} else {
+			ex.Module, ex.Type = "", orig
+		}
+		return
+	}
+}
+
+// remapFrame returns the original frames of an obfuscated frame, outermost
+// inlined call first, or nil if its class is not in the mapping. A frame of
+// an unknown or ambiguous method only gets its class mapped.
+func (m *mapping) remapFrame(f event.Frame) []event.Frame {
+	cls := m.classes[f.Module]
+	if cls == nil {
+		return nil
+	}
+	methods := cls.lookup(f.Function, f.Lineno)
+	if f.Function == "" || methods == nil {
+		f.Module = cls.name
+		f.Filename = m.sourceFile(cls.name, f.Filename)
+		return []event.Frame{f}
+	}
+
+	frames := make([]event.Frame, len(methods))
+	for i, meth := range methods {
+		g := f
+		g.Module = cls.name
+		if meth.class != "" {
+			g.Module = meth.class
+		}
+		g.Function = meth.name
+		g.Lineno = meth.remap(f.Lineno)
+		g.Filename = m.sourceFile(g.Module, f.Filename)
+		if g.AbsPath != "" {
+			g.AbsPath = g.Filename
+		}
+		frames[len(methods)-1-i] = g
+	}
+	return frames
+}
+
+// mapping returns the parsed mapping file at path.
+func (s *Symbolicator) mapping(path string) (*mapping, error) {
+	idx, err := s.mappingIndex()
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := idx.parsed[path]; ok {
+		return m, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := parseMapping(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	idx.parsed[path] = m
+	return m, nil
+}
+
+// mappingIndex is the mapping files of a symbolicator, with their UUIDs as
+// far as they have been computed.
+type mappingIndex struct {
+	paths  []string
+	uuids  map[string]string // content UUID by path
+	parsed map[string]*mapping
+}
+
+func (s *Symbolicator) mappingIndex() (*mappingIndex, error) {
+	if s.mappings != nil {
+		return s.mappings, nil
+	}
+	idx := &mappingIndex{uuids: map[string]string{}, parsed: map[string]*mapping{}}
+	for _, root := range s.Mappings {
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() {
+				idx.paths = append(idx.paths, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.mappings = idx
+	return idx, nil
+}
+
+// locateMapping returns the path of the mapping file with a UUID, or an
+// empty string. Files named after the UUID, or in a directory named after
+// it, are taken as is. Other files match if the UUID of their contents does.
+func (s *Symbolicator) locateMapping(uuid string) (string, error) {
+	uuid = strings.ToLower(uuid)
+	idx, err := s.mappingIndex()
+	if err != nil {
+		return "", err
+	}
+	for _, p := range idx.paths {
+		name := strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+		if strings.EqualFold(name, uuid) || strings.EqualFold(filepath.Base(filepath.Dir(p)), uuid) {
+			return p, nil
+		}
+	}
+	for _, p := range idx.paths {
+		id, ok := idx.uuids[p]
+		if !ok {
+			if id, err = fileMappingUUID(p); err != nil {
+				return "", err
+			}
+			idx.uuids[p] = id
+		}
+		if id == uuid {
+			return p, nil
+		}
+	}
+	return "", nil
+}
+
+// fileMappingUUID returns the UUID of the contents of a mapping file.
+func fileMappingUUID(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha1.New()
+	h.Write(mappingNamespace)
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return formatUUID(h.Sum(nil)), nil
+}
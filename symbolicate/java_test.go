@@ -0,0 +1,105 @@
+package symbolicate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+const mappingUUID = "ea338d3c-b49c-5411-a9b6-9aabb39e062a"
+
+// javaEvent is an exception in an app obfuscated with testdata/mapping.txt.
+const javaEvent = `{"platform":"java",` +
+	`"exception":{"values":[{"type":"b","module":"a","value":"boom","stacktrace":{"frames":[` +
+	`{"module":"java.lang.Thread","function":"run","filename":"Thread.java","lineno":1012},` +
+	`{"module":"io.sentry.sample.MainActivity","function":"onCreate","filename":"SourceFile","lineno":5,"in_app":true},` +
+	`{"module":"a.a","function":"b","filename":"SourceFile","lineno":3,"in_app":true}]}}]},` +
+	`"debug_meta":{"images":[{"type":"proguard","uuid":"` + mappingUUID + `"}]}}`
+
+func TestDeobfuscate(t *testing.T) {
+	ev, err := event.Parse([]byte(javaEvent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats, err := (&Symbolicator{Mappings: []string{"testdata/mapping.txt"}}).Symbolicate(ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Frames != 3 || stats.Symbolicated != 2 || len(stats.Missing) != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	ex := ev.Exception[0]
+	if ex.Module != "io.sentry.sample" || ex.Type != "Crasher$CrashException" || ex.Value != "boom" {
+		t.Errorf("exception = %s.%s: %s", ex.Module, ex.Type, ex.Value)
+	}
+	want := []struct {
+		module, function, filename string
+		line                       int
+	}{
+		{"java.lang.Thread", "run", "Thread.java", 1012},
+		{"io.sentry.sample.MainActivity", "onCreate", "MainActivity.kt", 29},
+		{"io.sentry.sample.Crasher", "crash", "Crasher.kt", 12},
+		{"io.sentry.sample.Crasher", "throwNested", "Crasher.kt", 21},
+	}
+	frames := ex.Stacktrace.Frames
+	if len(frames) != len(want) {
+		t.Fatalf("got %d frames, want %d: %+v", len(frames), len(want), frames)
+	}
+	for i, w := range want {
+		f := frames[i]
+		if f.Module != w.module || f.Function != w.function || f.Filename != w.filename || f.Lineno != w.line {
+			t.Errorf("frame %d = %s.%s (%s:%d), want %s.%s (%s:%d)", i, f.Module, f.Function, f.Filename, f.Lineno,
+				w.module, w.function, w.filename, w.line)
+		}
+	}
+	if f := frames[2]; f.InApp == nil || !*f.InApp {
+		t.Errorf("inlined frame lost in_app: %+v", f)
+	}
+}
+
+func TestDeobfuscateMissing(t *testing.T) {
+	ev, err := event.Parse([]byte(javaEvent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// A mapping file that is neither named after the UUID nor has it
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "mapping.txt"), []byte("a -> b:\n"), 0o644)
+
+	stats, err := (&Symbolicator{Mappings: []string{dir}}).Symbolicate(ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Symbolicated != 0 || len(stats.Missing) != 1 || stats.Missing[0].UUID != mappingUUID {
+		t.Errorf("stats = %+v", stats)
+	}
+	if ev.Exception[0].Type != "b" {
+		t.Errorf("exception type = %q, want it untouched", ev.Exception[0].Type)
+	}
+}
+
+func TestLocateMapping(t *testing.T) {
+	data, err := os.ReadFile("testdata/mapping.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := event.DebugImage{Type: "proguard", UUID: "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0"}
+	for _, name := range []string{img.UUID + ".txt", img.UUID + "/mapping.txt"} {
+		dir := t.TempDir()
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		os.WriteFile(p, data, 0o644)
+		if got, err := (&Symbolicator{Mappings: []string{dir}}).Locate(img); err != nil || got != p {
+			t.Errorf("Locate = %q, %v, want %q", got, err, p)
+		}
+	}
+
+	// By the UUID of the contents
+	img.UUID = mappingUUID
+	if got, err := (&Symbolicator{Mappings: []string{"testdata"}}).Locate(img); err != nil || got != filepath.Join("testdata", "mapping.txt") {
+		t.Errorf("Locate = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,310 @@
+package symbolicate
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// unminify maps JavaScript frames, which have the URL of a minified file
+// and a position in it, back to the original sources with source maps.
+// Frames get the original function, file and position, and the source lines
+// around it if the source map embeds the sources.
+func (s *Symbolicator) unminify(ev *event.Event, stats *Stats) error {
+	images := map[string]event.DebugImage{}
+	if ev.DebugMeta != nil {
+		for _, img := range ev.DebugMeta.Images {
+			if img.Type == "sourcemap" && img.CodeFile != "" && img.DebugID != "" {
+				images[img.CodeFile] = img
+			}
+		}
+	}
+	missing := map[string]bool{}
+	for _, st := range stacktraces(ev) {
+		for i := range st.Frames {
+			frame := &st.Frames[i]
+			if frame.AbsPath == "" || frame.Lineno == 0 {
+				continue
+			}
+			img, ok := images[frame.AbsPath]
+			file, err := s.minified(frame.AbsPath, img.DebugID)
+			if err != nil {
+				return err
+			}
+			if file == nil {
+				if ok {
+					stats.Frames++
+					if !missing[img.DebugID] {
+						missing[img.DebugID] = true
+						stats.Missing = append(stats.Missing, img)
+					}
+				}
+				continue
+			}
+			stats.Frames++
+			if file.remapFrame(frame) {
+				stats.Symbolicated++
+			}
+		}
+	}
+	return nil
+}
+
+// minifiedFile is a minified JavaScript file and its source map. The lines
+// of the file are nil if only the source map was found.
+type minifiedFile struct {
+	lines     []string
+	sourceMap *sourceMap
+}
+
+// remapFrame maps a frame to the original source, and reports whether its
+// position is mapped.
+func (f *minifiedFile) remapFrame(frame *event.Frame) bool {
+	line, col := frame.Lineno-1, max(frame.Colno-1, 0)
+	sm := f.sourceMap
+	seg, ok := sm.lookup(line, col)
+	if !ok {
+		return false
+	}
+	if name := sm.originalFunction(f.lines, frame.Function, line, col); name != "" {
+		frame.Function = name
+	}
+	frame.AbsPath = sm.sourceURL(seg.source, frame.AbsPath)
+	frame.Filename = sourceFilename(frame.AbsPath)
+	frame.Lineno, frame.Colno = seg.line+1, seg.col+1
+	if pre, current, post, ok := sm.context(seg.source, seg.line); ok {
+		frame.PreContext, frame.ContextLine, frame.PostContext = pre, current, post
+	}
+	return true
+}
+
+// minified returns the minified file at a URL with its source map, or nil
+// if there is none. The source map is looked up by debug ID if there is one,
+// and else by the URL prefixes of the source map directories. A directory
+// without a URL prefix is only searched by debug ID.
+func (s *Symbolicator) minified(url, debugID string) (*minifiedFile, error) {
+	key := url + "\x00" + debugID
+	if f, ok := s.minifiedFiles[key]; ok {
+		return f, nil
+	}
+	f, err := s.findMinified(url, debugID)
+	if err != nil {
+		return nil, err
+	}
+	if s.minifiedFiles == nil {
+		s.minifiedFiles = map[string]*minifiedFile{}
+	}
+	s.minifiedFiles[key] = f
+	return f, nil
+}
+
+func (s *Symbolicator) findMinified(url, debugID string) (*minifiedFile, error) {
+	if debugID != "" {
+		mapPath, minifiedPath, err := s.locateSourceMap(debugID)
+		if err != nil {
+			return nil, err
+		}
+		if mapPath != "" {
+			return loadMinified(minifiedPath, mapPath)
+		}
+		if minifiedPath != "" {
+			return s.openMinified(minifiedPath)
+		}
+	}
+	for _, spec := range s.SourceMaps {
+		prefix, dir := splitRule(spec)
+		rest, ok := matchURLPrefix(url, prefix)
+		if !ok || !filepath.IsLocal(filepath.FromSlash(rest)) {
+			continue
+		}
+		f, err := s.openMinified(filepath.Join(dir, filepath.FromSlash(rest)))
+		if f != nil || err != nil {
+			return f, err
+		}
+	}
+	return nil, nil
+}
+
+// matchURLPrefix returns the rest of a URL after a prefix, without the query
+// and fragment. A prefix that starts with ~/ matches the path of a URL on
+// any host, as in Sentry's release artifacts.
+func matchURLPrefix(url, prefix string) (string, bool) {
+	if prefix == "" {
+		return "", false
+	}
+	url, _, _ = strings.Cut(url, "#")
+	url, _, _ = strings.Cut(url, "?")
+	if p, ok := strings.CutPrefix(prefix, "~/"); ok {
+		if _, rest, ok := strings.Cut(url, "://"); ok {
+			if i := strings.IndexByte(rest, '/'); i >= 0 {
+				url = rest[i:]
+			}
+		}
+		prefix = "/" + p
+	}
+	rest, ok := strings.CutPrefix(url, prefix)
+	if !ok {
+		return "", false
+	}
+	rest = strings.TrimPrefix(rest, "/")
+	return rest, rest != ""
+}
+
+// openMinified reads a minified file and the source map that its
+// sourceMappingURL comment refers to, or that is next to it with a .map
+// suffix. Without the minified file, the source map next to where it would
+// be is read alone. It returns nil if there is no source map, or none that
+// parses and is in a source map directory.
+func (s *Symbolicator) openMinified(p string) (*minifiedFile, error) {
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return loadMinified("", p+".map")
+	}
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(data), "\n")
+	mapPath := p + ".map"
+	if u := sourceMappingURL(lines); u != "" {
+		inline, err := dataURLSourceMap(u)
+		if err != nil {
+			return nil, nil
+		}
+		if inline != nil {
+			sm, err := parseSourceMap(inline)
+			if err != nil {
+				return nil, nil
+			}
+			return &minifiedFile{lines: lines, sourceMap: sm}, nil
+		}
+		if strings.Contains(u, "://") {
+			u = path.Base(u)
+		}
+		u, _, _ = strings.Cut(u, "?")
+		mapPath = filepath.Join(filepath.Dir(p), filepath.FromSlash(u))
+		if !s.inSourceMapDir(mapPath) {
+			return nil, nil
+		}
+	}
+	f, err := loadMinified("", mapPath)
+	if f != nil {
+		f.lines = lines
+	}
+	return f, err
+}
+
+// loadMinified reads a source map, and the minified file it belongs to if
+// given. It returns nil if the source map does not exist or does not parse.
+func loadMinified(minifiedPath, mapPath string) (*minifiedFile, error) {
+	data, err := os.ReadFile(mapPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sm, err := parseSourceMap(data)
+	if err != nil {
+		return nil, nil
+	}
+	f := &minifiedFile{sourceMap: sm}
+	if minifiedPath != "" {
+		data, err := os.ReadFile(minifiedPath)
+		if err != nil {
+			return nil, err
+		}
+		f.lines = strings.Split(string(data), "\n")
+	}
+	return f, nil
+}
+
+// inSourceMapDir reports whether a path is in one of the source map
+// directories.
+func (s *Symbolicator) inSourceMapDir(p string) bool {
+	for _, spec := range s.SourceMaps {
+		_, dir := splitRule(spec)
+		if rel, err := filepath.Rel(dir, p); err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceMapIndex is the source maps and minified files in the source map
+// directories by their debug IDs.
+type sourceMapIndex struct {
+	maps     map[string]string
+	minified map[string]string
+}
+
+// locateSourceMap returns the paths of the source map and the minified file
+// with a debug ID, either of which is empty if not found.
+func (s *Symbolicator) locateSourceMap(debugID string) (mapPath, minifiedPath string, err error) {
+	if s.sourceMaps == nil {
+		if err := s.indexSourceMaps(); err != nil {
+			return "", "", err
+		}
+	}
+	id := strings.ToLower(debugID)
+	return s.sourceMaps.maps[id], s.sourceMaps.minified[id], nil
+}
+
+// indexSourceMaps reads the debug IDs of the source maps and minified files
+// in the source map directories. Source maps declare theirs in a debug_id
+// or debugId field, and minified files in a debugId comment. Files that
+// cannot be read are skipped.
+func (s *Symbolicator) indexSourceMaps() error {
+	idx := &sourceMapIndex{maps: map[string]string{}, minified: map[string]string{}}
+	for _, spec := range s.SourceMaps {
+		_, dir := splitRule(spec)
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if p == dir {
+					return err
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			switch filepath.Ext(p) {
+			case ".map":
+				data, err := os.ReadFile(p)
+				if err != nil {
+					return nil
+				}
+				var ids struct {
+					DebugID      string `json:"debug_id"`
+					DebugIDCamel string `json:"debugId"`
+				}
+				if json.Unmarshal(data, &ids) != nil {
+					return nil
+				}
+				if id := strings.ToLower(cmp.Or(ids.DebugID, ids.DebugIDCamel)); id != "" {
+					idx.maps[id] = p
+				}
+			case ".js", ".mjs", ".cjs":
+				data, err := os.ReadFile(p)
+				if err != nil {
+					return nil
+				}
+				if id := debugIDComment(strings.Split(string(data), "\n")); id != "" {
+					idx.minified[id] = p
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	s.sourceMaps = idx
+	return nil
+}
@@ -0,0 +1,226 @@
+package symbolicate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+const sourceMapDebugID = "d4a5b1b2-8c3e-4f6a-9b7d-1e2f3a4b5c6d"
+
+// browserEvent is an exception thrown by testdata/sourcemaps/static/app.min.js.
+const browserEvent = `{"platform":"javascript",` +
+	`"exception":{"values":[{"type":"Error","value":"boom","stacktrace":{"frames":[` +
+	`{"function":"run","filename":"/static/app.min.js","abs_path":"https://example.com/static/app.min.js","lineno":1,"colno":103,"in_app":true},` +
+	`{"function":"t","filename":"/static/app.min.js","abs_path":"https://example.com/static/app.min.js","lineno":1,"colno":46,"in_app":true},` +
+	`{"function":"n","filename":"/static/app.min.js","abs_path":"https://example.com/static/app.min.js?v=2","lineno":1,"colno":32,"in_app":true}]}}]}}`
+
+func TestUnminify(t *testing.T) {
+	for _, prefix := range []string{"https://example.com", "~/static", "https://example.com/static/"} {
+		t.Run(prefix, func(t *testing.T) {
+			dir := "testdata/sourcemaps"
+			if prefix != "https://example.com" {
+				dir += "/static"
+			}
+			ev, err := event.Parse([]byte(browserEvent))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			stats, err := (&Symbolicator{SourceMaps: []string{prefix + "=" + dir}}).Symbolicate(ev)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stats.Frames != 3 || stats.Symbolicated != 3 || len(stats.Missing) != 0 {
+				t.Errorf("stats = %+v", stats)
+			}
+			checkUnminified(t, ev)
+		})
+	}
+}
+
+func checkUnminified(t *testing.T, ev *event.Event) {
+	t.Helper()
+	want := []struct {
+		function    string
+		line, col   int
+		contextLine string
+	}{
+		{"run", 11, 3, "  crash();"},
+		{"crash", 6, 3, `  throw new Error("boom");`},
+		{"greet", 2, 22, `  return "Hello, " + name;`},
+	}
+	frames := ev.Exception[0].Stacktrace.Frames
+	for i, w := range want {
+		f := frames[i]
+		if f.Function != w.function || f.Lineno != w.line || f.Colno != w.col || f.ContextLine != w.contextLine {
+			t.Errorf("frame %d = %s:%d:%d %q, want %s:%d:%d %q", i, f.Function, f.Lineno, f.Colno, f.ContextLine,
+				w.function, w.line, w.col, w.contextLine)
+		}
+		if f.Filename != "src/app.js" || f.AbsPath != "webpack:///./src/app.js" || len(f.PreContext) == 0 || len(f.PostContext) == 0 {
+			t.Errorf("frame %d: filename %q, abs_path %q, pre_context %q", i, f.Filename, f.AbsPath, f.PreContext)
+		}
+		if f.InApp == nil || !*f.InApp {
+			t.Errorf("frame %d lost in_app", i)
+		}
+	}
+}
+
+func TestUnminifyDebugID(t *testing.T) {
+	image := `,"debug_meta":{"images":[{"type":"sourcemap","code_file":"https://example.com/static/app.min.js","debug_id":"` + sourceMapDebugID + `"}]}}`
+	ev, err := event.Parse([]byte(browserEvent[:len(browserEvent)-1] + image))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := &Symbolicator{SourceMaps: []string{"testdata/sourcemaps"}}
+	stats, err := s.Symbolicate(ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The third frame's URL has a query, so it has no debug ID and the
+	// directory has no URL prefix to find it by
+	if stats.Frames != 2 || stats.Symbolicated != 2 || len(stats.Missing) != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	frames := ev.Exception[0].Stacktrace.Frames
+	if f := frames[1]; f.Function != "crash" || f.Lineno != 6 {
+		t.Errorf("frame = %s:%d", f.Function, f.Lineno)
+	}
+	if f := frames[2]; f.Function != "n" || f.Lineno != 1 {
+		t.Errorf("frame without debug ID = %s:%d", f.Function, f.Lineno)
+	}
+
+	path, err := s.Locate(ev.DebugMeta.Images[0])
+	if err != nil || path != "testdata/sourcemaps/static/app.min.js.map" {
+		t.Errorf("Locate = %q, %v", path, err)
+	}
+}
+
+func TestUnminifyUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions do not apply to root")
+	}
+	dir := t.TempDir()
+	sourceMap, err := os.ReadFile("testdata/sourcemaps/static/app.min.js.map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Join(dir, "a", "locked"), 0o755)
+	os.MkdirAll(filepath.Join(dir, "b"), 0o755)
+	os.WriteFile(filepath.Join(dir, "a", "private.js.map"), sourceMap, 0)
+	os.WriteFile(filepath.Join(dir, "b", "app.min.js.map"), sourceMap, 0o644)
+	os.Chmod(filepath.Join(dir, "a", "locked"), 0)
+	t.Cleanup(func() { os.Chmod(filepath.Join(dir, "a", "locked"), 0o755) })
+
+	img := event.DebugImage{Type: "sourcemap", DebugID: sourceMapDebugID}
+	got, err := (&Symbolicator{SourceMaps: []string{dir}}).Locate(img)
+	if want := filepath.Join(dir, "b", "app.min.js.map"); err != nil || got != want {
+		t.Errorf("Locate = %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestUnminifyMissing(t *testing.T) {
+	image := `,"debug_meta":{"images":[{"type":"sourcemap","code_file":"https://example.com/static/app.min.js","debug_id":"00000000-0000-0000-0000-000000000000"}]}}`
+	ev, err := event.Parse([]byte(browserEvent[:len(browserEvent)-1] + image))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats, err := (&Symbolicator{SourceMaps: []string{"https://example.org=testdata/sourcemaps"}}).Symbolicate(ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Frames != 2 || stats.Symbolicated != 0 || len(stats.Missing) != 1 || stats.Missing[0].DebugID != "00000000-0000-0000-0000-000000000000" {
+		t.Errorf("stats = %+v", stats)
+	}
+	if f := ev.Exception[0].Stacktrace.Frames[1]; f.Function != "t" || f.Lineno != 1 || f.Colno != 46 {
+		t.Errorf("frame = %s:%d:%d, want it untouched", f.Function, f.Lineno, f.Colno)
+	}
+}
+
+// copySourceMaps copies the test minified file and source map to a
+// directory, with the source map at mapName next to the minified file.
+func copySourceMaps(t *testing.T, dir, minified, mapName string) {
+	t.Helper()
+	for name, src := range map[string]string{minified: "app.min.js", mapName: "app.min.js.map"} {
+		data, err := os.ReadFile(filepath.Join("testdata/sourcemaps/static", src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUnminifyOutsideDir(t *testing.T) {
+	dir := t.TempDir()
+	copySourceMaps(t, dir, "secret/app.min.js", "secret/app.min.js.map")
+	ev, err := event.Parse([]byte(strings.ReplaceAll(browserEvent, "https://example.com/static/", "https://example.com/static/../../secret/")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := &Symbolicator{SourceMaps: []string{"https://example.com/static=" + filepath.Join(dir, "maps/static")}}
+	if stats, err := s.Symbolicate(ev); err != nil || stats.Symbolicated != 0 {
+		t.Errorf("URL outside the directory: stats = %+v, err = %v", stats, err)
+	}
+
+	// A minified file in the directory whose sourceMappingURL leads out
+	data, err := os.ReadFile("testdata/sourcemaps/static/app.min.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.ReplaceAll(string(data), "sourceMappingURL=app.min.js.map", "sourceMappingURL=../../secret/app.min.js.map"))
+	os.MkdirAll(filepath.Join(dir, "maps/static"), 0o755)
+	os.WriteFile(filepath.Join(dir, "maps/static/app.min.js"), data, 0o644)
+	ev, err = event.Parse([]byte(browserEvent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats, err := s.Symbolicate(ev); err != nil || stats.Symbolicated != 0 {
+		t.Errorf("sourceMappingURL outside the directory: stats = %+v, err = %v", stats, err)
+	}
+}
+
+func TestUnminifyInvalidSourceMap(t *testing.T) {
+	dir := t.TempDir()
+	copySourceMaps(t, dir, "static/app.min.js", "static/app.min.js.map")
+	os.WriteFile(filepath.Join(dir, "static/app.min.js.map"), []byte(`{"version":3,"mappings":"g"}`), 0o644)
+	image := `,"debug_meta":{"images":[{"type":"sourcemap","code_file":"https://example.com/static/app.min.js","debug_id":"` + sourceMapDebugID + `"}]}}`
+	ev, err := event.Parse([]byte(browserEvent[:len(browserEvent)-1] + image))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats, err := (&Symbolicator{SourceMaps: []string{"https://example.com=" + dir}}).Symbolicate(ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Frames != 2 || stats.Symbolicated != 0 || len(stats.Missing) != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestMatchURLPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		url, prefix, want string
+		ok                bool
+	}{
+		{"https://example.com/static/app.js", "https://example.com/static", "app.js", true},
+		{"https://example.com/static/app.js?v=1#x", "https://example.com/static/", "app.js", true},
+		{"https://cdn.example.com/static/app.js", "~/static", "app.js", true},
+		{"https://cdn.example.com/static/app.js", "~/", "static/app.js", true},
+		{"https://example.com/static/app.js", "https://example.org", "", false},
+		{"https://example.com/static", "https://example.com/static", "", false},
+		{"https://example.com/static/app.js", "", "", false},
+	} {
+		got, ok := matchURLPrefix(tt.url, tt.prefix)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("matchURLPrefix(%q, %q) = %q, %v, want %q, %v", tt.url, tt.prefix, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package symbolicate
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// symbolicateNative resolves native frames, which have instruction
+// addresses, with ELF debug files. Resolved frames get their function and
+// source location, and a frame that spans inlined calls is replaced by one
+// frame per call. Frames that already have a line number are left alone.
+func (s *Symbolicator) symbolicateNative(ev *event.Event, stats *Stats) error {
+	missing := map[int]bool{}
+	for _, st := range stacktraces(ev) {
+		var frames []event.Frame
+		for i, frame := range st.Frames {
+			// Return addresses point past the call, so look up the call
+			// itself in all but the innermost frame
+			resolved, img, err := s.resolve(ev.DebugMeta.Images, frame, i < len(st.Frames)-1)
+			if err != nil {
+				return err
+			}
+			if frame.InstructionAddr != "" && frame.Lineno == 0 {
+				stats.Frames++
+			}
+			switch {
+			case resolved != nil:
+				stats.Symbolicated++
+				frames = append(frames, resolved...)
+			case img >= 0 && !missing[img]:
+				missing[img] = true
+				stats.Missing = append(stats.Missing, ev.DebugMeta.Images[img])
+				frames = append(frames, frame)
+			default:
+				frames = append(frames, frame)
+			}
+		}
+		st.Frames = frames
+	}
+	return nil
+}
+
+// resolve symbolicates a frame, returning the frames for its inlined calls
+// outermost first, or nil if it could not be resolved. It also returns the
+// index of the image the frame points into, or -1.
+func (s *Symbolicator) resolve(images []event.DebugImage, frame event.Frame, caller bool) ([]event.Frame, int, error) {
+	if frame.InstructionAddr == "" || frame.Lineno > 0 {
+		return nil, -1, nil
+	}
+	addr, err := event.ParseAddr(frame.InstructionAddr)
+	if err != nil {
+		return nil, -1, nil
+	}
+	i := findImage(images, frame, addr)
+	if i < 0 {
+		return nil, -1, nil
+	}
+	img := images[i]
+	df, err := s.debugFile(img)
+	if err != nil || df == nil {
+		return nil, i, err
+	}
+
+	imageAddr, _ := event.ParseAddr(img.ImageAddr)
+	vmaddr := df.vmaddr
+	if img.ImageVMAddr != "" {
+		if vmaddr, err = event.ParseAddr(img.ImageVMAddr); err != nil {
+			return nil, i, nil
+		}
+	}
+	pc := addr - imageAddr + vmaddr
+	if caller && pc > 0 {
+		pc--
+	}
+	locations, err := df.lookup(pc)
+	if err != nil {
+		return nil, i, fmt.Errorf("resolving %s in %s: %w", frame.InstructionAddr, df.path, err)
+	}
+	if len(locations) == 0 {
+		return nil, i, nil
+	}
+
+	frames := make([]event.Frame, len(locations))
+	for n, loc := range locations {
+		f := frame
+		f.Function = loc.function
+		if loc.linkage != "" && loc.linkage != loc.function {
+			f.RawFunction = loc.linkage
+		}
+		if loc.file != "" {
+			f.AbsPath = loc.file
+			f.Filename = base(loc.file)
+		}
+		f.Lineno = loc.line
+		f.Colno = loc.col
+		if loc.symbolAddr != 0 {
+			f.SymbolAddr = fmt.Sprintf("0x%x", loc.symbolAddr-vmaddr+imageAddr)
+		}
+		if f.Package == "" {
+			f.Package = img.CodeFile
+		}
+		// Outermost call first
+		frames[len(locations)-1-n] = f
+	}
+	return frames, i, nil
+}
+
+// findImage returns the index of the image that a frame points into, or
+// -1.
+func findImage(images []event.DebugImage, frame event.Frame, addr uint64) int {
+	for i, img := range images {
+		if frame.ImageAddr != "" && frame.ImageAddr == img.ImageAddr || img.Contains(addr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// debugFile returns the ELF debug file of an image, or nil if there is
+// none.
+func (s *Symbolicator) debugFile(img event.DebugImage) (*debugFile, error) {
+	id := imageBuildID(img)
+	if id == "" {
+		return nil, nil
+	}
+	if df, ok := s.files[id]; ok {
+		return df, nil
+	}
+	if s.files == nil {
+		s.files = map[string]*debugFile{}
+	}
+	path, err := s.Locate(img)
+	if err != nil || path == "" {
+		s.files[id] = nil
+		return nil, err
+	}
+	df, err := openDebugFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening debug file: %w", err)
+	}
+	s.files[id] = df
+	return df, nil
+}
+
+// imageBuildID returns the build ID of an ELF image in hex, or an empty
+// string for other images. Without a code ID, only the first 16 bytes are
+// known, from the debug ID.
+func imageBuildID(img event.DebugImage) string {
+	if img.Type != "elf" && img.Type != "" {
+		return ""
+	}
+	if img.CodeID != "" {
+		return strings.ToLower(img.CodeID)
+	}
+	id := strings.ReplaceAll(strings.ToLower(img.DebugID), "-", "")
+	if len(id) < 32 {
+		return ""
+	}
+	b, err := hex.DecodeString(id[:32])
+	if err != nil {
+		return ""
+	}
+	// The first three fields of the debug ID are little-endian
+	b[0], b[1], b[2], b[3] = b[3], b[2], b[1], b[0]
+	b[4], b[5] = b[5], b[4]
+	b[6], b[7] = b[7], b[6]
+	return hex.EncodeToString(b)
+}
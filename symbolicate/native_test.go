@@ -0,0 +1,118 @@
+package symbolicate
+
+import (
+	"testing"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// crashEvent is a crash in testdata/crash, loaded at 0x10000. It was built
+// with gcc -O2 -g -fno-omit-frame-pointer -fdebug-prefix-map=$PWD=/src
+// -Wl,--build-id=sha1 crash.c. The innermost frame is the crashing store in
+// write_value, which is inlined twice into run, which main calls.
+const crashEvent = `{"platform":"native",` +
+	`"exception":{"values":[{"type":"SIGSEGV","stacktrace":{"frames":[` +
+	`{"instruction_addr":"0x7f0000001000","package":"/usr/lib/libc.so.6"},` +
+	`{"instruction_addr":"0x11049"},` +
+	`{"instruction_addr":"0x1114a","in_app":true}]}}]},` +
+	`"debug_meta":{"images":[` +
+	`{"type":"elf","code_file":"/usr/bin/crash","code_id":"89a79abce0b8c4c8e628e26bc0b8e10f38a90b1a","image_addr":"0x10000","image_size":16384},` +
+	`{"type":"elf","code_file":"/usr/lib/libc.so.6","code_id":"00112233445566778899aabbccddeeff00112233","image_addr":"0x7f0000000000","image_size":65536}]}}`
+
+func TestSymbolicate(t *testing.T) {
+	ev, err := event.Parse([]byte(crashEvent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats, err := (&Symbolicator{DebugDirs: []string{"testdata"}}).Symbolicate(ev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Frames != 3 || stats.Symbolicated != 2 {
+		t.Errorf("stats = %d of %d frames, want 2 of 3", stats.Symbolicated, stats.Frames)
+	}
+	if len(stats.Missing) != 1 || stats.Missing[0].CodeFile != "/usr/lib/libc.so.6" {
+		t.Errorf("missing = %+v", stats.Missing)
+	}
+
+	want := []struct {
+		function string
+		line     int
+		addr     string
+	}{
+		{"", 0, "0x7f0000001000"},
+		{"main", 22, "0x11049"},
+		{"run", 17, "0x1114a"},
+		{"trigger_crash", 12, "0x1114a"},
+		{"write_value", 7, "0x1114a"},
+	}
+	frames := ev.Exception[0].Stacktrace.Frames
+	if len(frames) != len(want) {
+		t.Fatalf("got %d frames, want %d: %+v", len(frames), len(want), frames)
+	}
+	for i, w := range want {
+		f := frames[i]
+		if f.Function != w.function || f.Lineno != w.line || f.InstructionAddr != w.addr {
+			t.Errorf("frame %d = %s:%d at %s, want %s:%d at %s", i, f.Function, f.Lineno, f.InstructionAddr, w.function, w.line, w.addr)
+		}
+	}
+	if f := frames[4]; f.Filename != "crash.c" || f.AbsPath != "/src/crash.c" || f.Package != "/usr/bin/crash" ||
+		f.SymbolAddr != "0x11140" || f.InApp == nil || !*f.InApp {
+		t.Errorf("innermost frame = %+v", f)
+	}
+	if f := frames[0]; f.Package != "/usr/lib/libc.so.6" || f.Function != "" {
+		t.Errorf("unresolved frame = %+v", f)
+	}
+}
+
+func TestSymbolicateDebugID(t *testing.T) {
+	ev, err := event.Parse([]byte(`{"exception":{"values":[{"stacktrace":{"frames":[{"instruction_addr":"0x1114a"}]}}]},` +
+		`"debug_meta":{"images":[{"type":"elf","debug_id":"bc9aa789-b8e0-c8c4-e628-e26bc0b8e10f","image_addr":"0x10000","image_size":16384}]}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := (&Symbolicator{DebugDirs: []string{"testdata"}}).Symbolicate(ev); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	frames := ev.Exception[0].Stacktrace.Frames
+	if len(frames) != 3 || frames[2].Function != "write_value" {
+		t.Errorf("frames = %+v", frames)
+	}
+}
+
+// cppCrashEvent is a crash in testdata/crash-cpp, loaded at 0x10000. It was
+// built from crash.ll, the IR that clang emits for crash.cpp, with
+// llc -O2 -filetype=obj and gcc -Wl,--build-id=sha1. As clang does, it has
+// the DWARF of app::run within the app namespace, with Writer::write inlined.
+const cppCrashEvent = `{"platform":"native",` +
+	`"exception":{"values":[{"type":"SIGSEGV","stacktrace":{"frames":[{"instruction_addr":"0x1113d"}]}}]},` +
+	`"debug_meta":{"images":[` +
+	`{"type":"elf","code_file":"/usr/bin/crash-cpp","code_id":"d90b1886c2ff60d9da2c1a66bb998610c49ad1a0","image_addr":"0x10000","image_size":16384}]}}`
+
+func TestSymbolicateNamespace(t *testing.T) {
+	ev, err := event.Parse([]byte(cppCrashEvent))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := (&Symbolicator{DebugDirs: []string{"testdata"}}).Symbolicate(ev); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []struct {
+		function, raw string
+		line          int
+	}{
+		{"run", "_ZN3app3runEi", 15},
+		{"write", "_ZN3app6Writer5writeEi", 9},
+	}
+	frames := ev.Exception[0].Stacktrace.Frames
+	if len(frames) != len(want) {
+		t.Fatalf("got %d frames, want %d: %+v", len(frames), len(want), frames)
+	}
+	for i, w := range want {
+		f := frames[i]
+		if f.Function != w.function || f.RawFunction != w.raw || f.Lineno != w.line || f.Filename != "crash.cpp" {
+			t.Errorf("frame %d = %s (%s) %s:%d, want %s (%s) crash.cpp:%d", i, f.Function, f.RawFunction, f.Filename, f.Lineno,
+				w.function, w.raw, w.line)
+		}
+	}
+}
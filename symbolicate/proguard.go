@@ -0,0 +1,223 @@
+package symbolicate
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// mapping is a ProGuard or R8 mapping file, which maps obfuscated class
+// and method names and line numbers back to the original ones.
+type mapping struct {
+	classes map[string]*class // by obfuscated name
+	files   map[string]string // source files by original class name
+}
+
+// class is a class of a mapping and its methods.
+type class struct {
+	name    string
+	methods map[string][]method // by obfuscated name, in mapping order
+}
+
+// method maps an obfuscated method and line range to the original. Methods
+// that share an obfuscated line range are inlined into one another,
+// innermost first.
+type method struct {
+	class              string // class of an inlined method, if not the own
+	name               string
+	obfStart, obfEnd   int
+	origStart, origEnd int
+}
+
+// parseMapping parses a mapping file. Fields and lines it does not
+// understand are skipped.
+func parseMapping(r io.Reader) (*mapping, error) {
+	m := &mapping{classes: map[string]*class{}, files: map[string]string{}}
+	var cls *class
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		line := s.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "#"):
+			// R8 metadata, of which only the source file of a class matters
+			if cls == nil || line != trimmed {
+				continue
+			}
+			var meta struct {
+				ID       string `json:"id"`
+				FileName string `json:"fileName"`
+			}
+			if json.Unmarshal([]byte(strings.TrimSpace(trimmed[1:])), &meta) == nil && meta.ID == "sourceFile" {
+				m.files[cls.name] = meta.FileName
+			}
+		case line == trimmed:
+			orig, obf, ok := strings.Cut(strings.TrimSuffix(trimmed, ":"), " -> ")
+			if !ok {
+				cls = nil
+				continue
+			}
+			cls = &class{name: orig, methods: map[string][]method{}}
+			m.classes[obf] = cls
+		case cls != nil:
+			if meth, obf, ok := parseMethod(trimmed); ok {
+				if i := strings.LastIndexByte(meth.name, '.'); i >= 0 {
+					meth.class, meth.name = meth.name[:i], meth.name[i+1:]
+				}
+				cls.methods[obf] = append(cls.methods[obf], meth)
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseMethod parses a method line of the form
+// [obfStart:obfEnd:]type name(args)[:origStart[:origEnd]] -> obf. Field
+// lines have no arguments and are not methods.
+func parseMethod(line string) (method, string, bool) {
+	var meth method
+	left, obf, ok := strings.Cut(line, " -> ")
+	if !ok {
+		return meth, "", false
+	}
+	open, close := strings.IndexByte(left, '('), strings.LastIndexByte(left, ')')
+	if open < 0 || close < open {
+		return meth, "", false
+	}
+
+	// The obfuscated line range precedes the return type
+	head := left[:open]
+	if n := strings.Count(head[:max(strings.IndexByte(head, ' '), 0)], ":"); n == 2 {
+		parts := strings.SplitN(head, ":", 3)
+		meth.obfStart, _ = strconv.Atoi(parts[0])
+		meth.obfEnd, _ = strconv.Atoi(parts[1])
+		head = parts[2]
+	}
+	_, meth.name, ok = strings.Cut(head, " ")
+	if !ok {
+		return meth, "", false
+	}
+
+	// The original line range follows the arguments
+	if tail := left[close+1:]; strings.HasPrefix(tail, ":") {
+		start, end, hasEnd := strings.Cut(tail[1:], ":")
+		meth.origStart, _ = strconv.Atoi(start)
+		meth.origEnd = meth.origStart
+		if hasEnd {
+			meth.origEnd, _ = strconv.Atoi(end)
+		}
+	}
+	return meth, obf, true
+}
+
+// remap maps an obfuscated line within a method to the original line.
+// Without an original range, the lines were left as they are.
+func (meth method) remap(line int) int {
+	switch {
+	case meth.origStart == 0:
+		return line
+	case meth.origEnd > meth.origStart && line >= meth.obfStart:
+		return meth.origStart + line - meth.obfStart
+	}
+	return meth.origStart
+}
+
+// lookup returns the original methods of an obfuscated method and line,
+// innermost inlined call first, or nil if the method is not mapped or is
+// ambiguous.
+func (cls *class) lookup(name string, line int) []method {
+	var ranged, unranged []method
+	for _, meth := range cls.methods[name] {
+		switch {
+		case meth.obfStart == 0 && meth.obfEnd == 0:
+			unranged = append(unranged, meth)
+		case line >= meth.obfStart && line <= meth.obfEnd:
+			ranged = append(ranged, meth)
+		}
+	}
+	if line > 0 && len(ranged) > 0 {
+		return ranged
+	}
+	candidates := unranged
+	if len(candidates) == 0 && line == 0 {
+		candidates = cls.methods[name]
+	}
+	// Overloads of one method are fine, different methods are ambiguous
+	for _, meth := range candidates {
+		if meth.name != candidates[0].name || meth.class != candidates[0].class {
+			return nil
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	meth := candidates[0]
+	if line == 0 {
+		meth.origStart = 0
+	}
+	return []method{meth}
+}
+
+// sourceFile returns the source file of an original class: the one given
+// in the mapping, or else the current one, unless it is a placeholder that
+// obfuscation left, in which case it is guessed from the outermost class.
+func (m *mapping) sourceFile(className, current string) string {
+	if file := m.files[className]; file != "" {
+		return file
+	}
+	if current != "" && current != "SourceFile" && current != "Unknown Source" {
+		return current
+	}
+	name := className[strings.LastIndexByte(className, '.')+1:]
+	if i := strings.IndexByte(name, '$'); i >= 0 {
+		name = name[:i]
+	}
+	return name + ".java"
+}
+
+// originalClass returns the original name of an obfuscated class, or an
+// empty string.
+func (m *mapping) originalClass(name string) string {
+	if cls := m.classes[name]; cls != nil {
+		return cls.name
+	}
+	return ""
+}
+
+// mappingNamespace is the namespace of the UUIDs that identify mapping
+// files by their contents: the version 5 UUID of guardsquare.com in the DNS
+// namespace.
+var mappingNamespace = uuid5([]byte{
+	0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1,
+	0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8,
+}, []byte("guardsquare.com"))
+
+func uuid5(namespace, name []byte) []byte {
+	h := sha1.New()
+	h.Write(namespace)
+	h.Write(name)
+	return uuidBytes(h.Sum(nil))
+}
+
+// uuidBytes turns a SHA-1 hash into the bytes of a version 5 UUID.
+func uuidBytes(sum []byte) []byte {
+	u := sum[:16]
+	u[6] = u[6]&0x0f | 0x50
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// formatUUID formats a SHA-1 hash as a version 5 UUID.
+func formatUUID(sum []byte) string {
+	u := uuidBytes(sum)
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
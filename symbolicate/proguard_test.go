@@ -0,0 +1,102 @@
+package symbolicate
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+func readTestMapping(t *testing.T) *mapping {
+	t.Helper()
+	f, err := os.Open("testdata/mapping.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := parseMapping(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return m
+}
+
+func TestParseMapping(t *testing.T) {
+	m := readTestMapping(t)
+	if got := m.originalClass("a.b"); got != "io.sentry.sample.Crasher$CrashException" {
+		t.Errorf("originalClass(a.b) = %q", got)
+	}
+	if got := m.originalClass("x"); got != "" {
+		t.Errorf("originalClass(x) = %q, want none", got)
+	}
+	cls := m.classes["a.a"]
+	if cls == nil || cls.name != "io.sentry.sample.Crasher" {
+		t.Fatalf("class a.a = %+v", cls)
+	}
+	if _, ok := cls.methods["a"]; !ok || len(cls.methods["a"]) != 1 {
+		t.Errorf("field a parsed as a method: %+v", cls.methods["a"])
+	}
+	want := method{name: "throwNested", obfStart: 2, obfEnd: 3, origStart: 20, origEnd: 21}
+	if got := cls.methods["b"]; len(got) != 1 || got[0] != want {
+		t.Errorf("method b = %+v, want %+v", got, want)
+	}
+	for class, want := range map[string]string{
+		"io.sentry.sample.Crasher":                "Crasher.kt",
+		"io.sentry.sample.Crasher$CrashException": "Crasher.java",
+	} {
+		if got := m.sourceFile(class, "SourceFile"); got != want {
+			t.Errorf("sourceFile(%s) = %q, want %q", class, got, want)
+		}
+	}
+}
+
+func TestMappingLookup(t *testing.T) {
+	m := readTestMapping(t)
+	tests := []struct {
+		class, method string
+		line          int
+		want          []string // class.method:line, innermost first
+	}{
+		{"a.a", "b", 3, []string{"io.sentry.sample.Crasher.throwNested:21"}},
+		{"a.a", "a", 1, []string{"io.sentry.sample.Crasher.crash:12"}},
+		{"io.sentry.sample.MainActivity", "onCreate", 2, []string{"io.sentry.sample.MainActivity.onCreate:26"}},
+		{"io.sentry.sample.MainActivity", "onCreate", 5, []string{"io.sentry.sample.Crasher.crash:12", "io.sentry.sample.MainActivity.onCreate:29"}},
+		{"io.sentry.sample.MainActivity", "onCreate", 7, []string{"io.sentry.sample.MainActivity.onCreate:31"}},
+		{"io.sentry.sample.MainActivity", "onCreate", 0, nil},
+		{"a.a", "c", 42, []string{"io.sentry.sample.Crasher.unranged:42"}},
+		{"a.a", "d", 0, []string{"io.sentry.sample.Crasher.overloaded:0"}},
+		{"a.a", "e", 0, nil},
+		{"a.a", "z", 1, nil},
+	}
+	for _, tt := range tests {
+		cls := m.classes[tt.class]
+		var got []string
+		for _, meth := range cls.lookup(tt.method, tt.line) {
+			class := meth.class
+			if class == "" {
+				class = cls.name
+			}
+			got = append(got, class+"."+meth.name+":"+strconv.Itoa(meth.remap(tt.line)))
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s.%s:%d = %v, want %v", tt.class, tt.method, tt.line, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s.%s:%d = %v, want %v", tt.class, tt.method, tt.line, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestMappingUUID(t *testing.T) {
+	// Computed independently with uuid5 in Python
+	got, err := fileMappingUUID("testdata/mapping.txt")
+	if err != nil || got != "ea338d3c-b49c-5411-a9b6-9aabb39e062a" {
+		t.Errorf("fileMappingUUID = %q, %v", got, err)
+	}
+	if got := formatUUID(mappingNamespace); got != "4f44f30f-24be-53d0-bab6-f47c7120ad6c" {
+		t.Errorf("namespace = %q", got)
+	}
+}
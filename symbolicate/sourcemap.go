@@ -0,0 +1,264 @@
+package symbolicate
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// sourceMap is a decoded source map, which maps positions in a generated
+// file back to positions in its sources.
+type sourceMap struct {
+	SourceRoot     string    `json:"sourceRoot"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent"`
+	Names          []string  `json:"names"`
+	Mappings       string    `json:"mappings"`
+	Sections       []any     `json:"sections"`
+	DebugID        string    `json:"debug_id"`
+	DebugIDCamel   string    `json:"debugId"`
+
+	lines [][]segment // by generated line
+}
+
+// segment maps a generated column to a source position. Source and name
+// are -1 if the segment has none.
+type segment struct {
+	genCol, source, line, col, name int
+}
+
+func parseSourceMap(data []byte) (*sourceMap, error) {
+	var sm sourceMap
+	if err := json.Unmarshal(data, &sm); err != nil {
+		return nil, err
+	}
+	if len(sm.Sections) > 0 {
+		return nil, errors.New("indexed source maps are not supported")
+	}
+	if sm.DebugID == "" {
+		sm.DebugID = sm.DebugIDCamel
+	}
+	var err error
+	if sm.lines, err = decodeMappings(sm.Mappings); err != nil {
+		return nil, fmt.Errorf("decoding mappings: %w", err)
+	}
+	return &sm, nil
+}
+
+// decodeMappings decodes the base64 VLQ mappings of a source map. Lines are
+// separated by semicolons and segments by commas. The generated column is
+// relative to the previous segment of the line, and the other fields to the
+// previous segment that has them.
+func decodeMappings(mappings string) ([][]segment, error) {
+	var lines [][]segment
+	var source, line, col, name int
+	for _, l := range strings.Split(mappings, ";") {
+		var segs []segment
+		genCol := 0
+		for _, s := range strings.Split(l, ",") {
+			if s == "" {
+				continue
+			}
+			fields, err := decodeVLQ(s)
+			if err != nil {
+				return nil, err
+			}
+			seg := segment{source: -1, name: -1}
+			switch len(fields) {
+			case 1, 4, 5:
+			default:
+				return nil, fmt.Errorf("segment %q has %d fields", s, len(fields))
+			}
+			genCol += fields[0]
+			seg.genCol = genCol
+			if len(fields) >= 4 {
+				source += fields[1]
+				line += fields[2]
+				col += fields[3]
+				seg.source, seg.line, seg.col = source, line, col
+			}
+			if len(fields) == 5 {
+				name += fields[4]
+				seg.name = name
+			}
+			segs = append(segs, seg)
+		}
+		sort.SliceStable(segs, func(i, j int) bool { return segs[i].genCol < segs[j].genCol })
+		lines = append(lines, segs)
+	}
+	return lines, nil
+}
+
+const base64Digits = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// decodeVLQ decodes the values of a segment. Each value is a run of base64
+// digits of 5 bits each, least significant first, with the sixth bit set on
+// all but the last. The lowest bit of the value is its sign.
+func decodeVLQ(s string) ([]int, error) {
+	var values []int
+	value, shift := 0, 0
+	for i := 0; i < len(s); i++ {
+		digit := strings.IndexByte(base64Digits, s[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base64 digit %q", s[i])
+		}
+		value += (digit & 31) << shift
+		if digit&32 != 0 {
+			shift += 5
+			continue
+		}
+		if value&1 != 0 {
+			values = append(values, -(value >> 1))
+		} else {
+			values = append(values, value>>1)
+		}
+		value, shift = 0, 0
+	}
+	if shift != 0 {
+		return nil, fmt.Errorf("truncated segment %q", s)
+	}
+	return values, nil
+}
+
+// lookup returns the segment that covers a zero-based generated position:
+// the last one of the line at or before the column.
+func (sm *sourceMap) lookup(line, col int) (segment, bool) {
+	if line < 0 || line >= len(sm.lines) {
+		return segment{}, false
+	}
+	segs := sm.lines[line]
+	i := sort.Search(len(segs), func(i int) bool { return segs[i].genCol > col })
+	if i == 0 || segs[i-1].source < 0 || segs[i-1].source >= len(sm.Sources) {
+		return segment{}, false
+	}
+	return segs[i-1], true
+}
+
+// sourceURL returns the URL of a source, resolved against the source root
+// and the URL of the generated file.
+func (sm *sourceMap) sourceURL(source int, base string) string {
+	src := sm.Sources[source]
+	if sm.SourceRoot != "" && !strings.Contains(src, "://") {
+		src = strings.TrimSuffix(sm.SourceRoot, "/") + "/" + src
+	}
+	if strings.Contains(src, "://") {
+		return src
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil || baseURL.Scheme == "" {
+		return src
+	}
+	ref, err := url.Parse(src)
+	if err != nil {
+		return src
+	}
+	return baseURL.ResolveReference(ref).String()
+}
+
+// sourceFilename returns the path of a source URL as Sentry shows it:
+// without the scheme and host, and for webpack sources also without the
+// leading ./ of a relative path.
+func sourceFilename(source string) string {
+	u, err := url.Parse(source)
+	if err != nil || u.Scheme == "" {
+		return strings.TrimPrefix(source, "./")
+	}
+	if u.Scheme == "webpack" {
+		return strings.TrimPrefix(strings.TrimPrefix(u.Path, "/"), "./")
+	}
+	return u.Path
+}
+
+// context returns the lines around a zero-based line of a source from the
+// embedded sources content.
+func (sm *sourceMap) context(source, line int) (pre []string, current string, post []string, ok bool) {
+	if source >= len(sm.SourcesContent) || sm.SourcesContent[source] == nil {
+		return nil, "", nil, false
+	}
+	return contextAround(splitLines(*sm.SourcesContent[source]), line)
+}
+
+// originalFunction guesses the original name of a minified function from
+// the generated source. Minifiers map the name of a function where it is
+// defined, so the closest mapped token before the position that spells the
+// minified name gives it. For a dotted name, only the last part is mapped.
+func (sm *sourceMap) originalFunction(generated []string, function string, line, col int) string {
+	prefix, token := "", function
+	if i := strings.LastIndexByte(function, '.'); i >= 0 {
+		prefix, token = function[:i+1], function[i+1:]
+	}
+	if token == "" || len(generated) == 0 {
+		return ""
+	}
+	for l := min(line, len(sm.lines)-1, len(generated)-1); l >= 0; l-- {
+		segs := sm.lines[l]
+		for i := len(segs) - 1; i >= 0; i-- {
+			seg := segs[i]
+			if l == line && seg.genCol > col || seg.name < 0 || seg.name >= len(sm.Names) {
+				continue
+			}
+			text := generated[l]
+			if strings.HasPrefix(text[min(seg.genCol, len(text)):], token) && !isIdentByte(text, seg.genCol+len(token)) {
+				return prefix + sm.Names[seg.name]
+			}
+		}
+	}
+	return ""
+}
+
+// isIdentByte reports whether the byte at i continues a JavaScript
+// identifier.
+func isIdentByte(s string, i int) bool {
+	if i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// sourceMappingURL returns the URL of the source map that a generated file
+// refers to in its last sourceMappingURL comment, or an empty string.
+func sourceMappingURL(generated []string) string {
+	for i := len(generated) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(generated[i])
+		for _, prefix := range []string{"//# sourceMappingURL=", "//@ sourceMappingURL="} {
+			if u, ok := strings.CutPrefix(line, prefix); ok {
+				return strings.TrimSpace(u)
+			}
+		}
+	}
+	return ""
+}
+
+// debugIDComment returns the debug ID that a generated file declares in a
+// debugId comment, or an empty string.
+func debugIDComment(generated []string) string {
+	for i := len(generated) - 1; i >= 0; i-- {
+		if id, ok := strings.CutPrefix(strings.TrimSpace(generated[i]), "//# debugId="); ok {
+			return strings.ToLower(strings.TrimSpace(id))
+		}
+	}
+	return ""
+}
+
+// dataURLSourceMap decodes a source map inlined in a data URL, or returns
+// nil if the URL is not one.
+func dataURLSourceMap(u string) ([]byte, error) {
+	rest, ok := strings.CutPrefix(u, "data:")
+	if !ok {
+		return nil, nil
+	}
+	meta, data, ok := strings.Cut(rest, ",")
+	if !ok {
+		return nil, errors.New("invalid data URL")
+	}
+	if strings.HasSuffix(meta, ";base64") {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	decoded, err := url.PathUnescape(data)
+	return []byte(decoded), err
+}
@@ -0,0 +1,159 @@
+package symbolicate
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func readTestSourceMap(t *testing.T) *sourceMap {
+	t.Helper()
+	data, err := os.ReadFile("testdata/sourcemaps/static/app.min.js.map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm, err := parseSourceMap(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return sm
+}
+
+func TestDecodeVLQ(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want []int
+	}{
+		{"AAAA", []int{0, 0, 0, 0}},
+		{"SAASA", []int{9, 0, 0, 9, 0}},
+		{"D", []int{-1}},
+		{"gB", []int{16}},
+		{"2HwBA", []int{123, 24, 0}},
+	} {
+		got, err := decodeVLQ(tt.in)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("decodeVLQ(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"g", "A!"} {
+		if _, err := decodeVLQ(in); err == nil {
+			t.Errorf("decodeVLQ(%q): want error", in)
+		}
+	}
+}
+
+func TestSourceMapLookup(t *testing.T) {
+	sm := readTestSourceMap(t)
+	if sm.DebugID != "d4a5b1b2-8c3e-4f6a-9b7d-1e2f3a4b5c6d" {
+		t.Errorf("debug ID = %q", sm.DebugID)
+	}
+	for _, tt := range []struct {
+		col, line, origCol int
+	}{
+		{0, 0, 0},
+		{45, 5, 2}, // throw
+		{50, 5, 2}, // within the same segment
+		{102, 10, 2},
+	} {
+		seg, ok := sm.lookup(0, tt.col)
+		if !ok || seg.line != tt.line || seg.col != tt.origCol {
+			t.Errorf("lookup(0, %d) = %+v, %v, want line %d col %d", tt.col, seg, ok, tt.line, tt.origCol)
+		}
+	}
+	if _, ok := sm.lookup(1, 0); ok {
+		t.Errorf("lookup past the mappings: want none")
+	}
+
+	pre, current, post, ok := sm.context(0, 5)
+	if !ok || current != `  throw new Error("boom");` || len(pre) != 5 || pre[0] != "function greet(name) {" || len(post) != 5 || post[0] != "}" {
+		t.Errorf("context = %q, %q, %q, %v", pre, current, post, ok)
+	}
+	if _, _, post, _ := sm.context(0, 10); len(post) != 1 {
+		t.Errorf("context at the end: post = %q", post)
+	}
+}
+
+func TestOriginalFunction(t *testing.T) {
+	sm := readTestSourceMap(t)
+	data, err := os.ReadFile("testdata/sourcemaps/static/app.min.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	for _, tt := range []struct {
+		function string
+		col      int
+		want     string
+	}{
+		{"t", 45, "crash"},
+		{"run", 102, "run"},
+		{"Object.t", 45, "Object.crash"},
+		{"x", 45, ""},
+		{"t", 20, ""}, // before t is defined
+	} {
+		if got := sm.originalFunction(lines, tt.function, 0, tt.col); got != tt.want {
+			t.Errorf("originalFunction(%q, %d) = %q, want %q", tt.function, tt.col, got, tt.want)
+		}
+	}
+	if got := sm.originalFunction(nil, "t", 0, 45); got != "" {
+		t.Errorf("without the minified file: %q", got)
+	}
+}
+
+func TestSourceFilename(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"webpack:///./src/app.js", "src/app.js"},
+		{"webpack://my-app/./src/app.js", "src/app.js"},
+		{"https://example.com/src/app.js", "/src/app.js"},
+		{"./src/app.js", "src/app.js"},
+	} {
+		if got := sourceFilename(tt.in); got != tt.want {
+			t.Errorf("sourceFilename(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSourceURL(t *testing.T) {
+	sm := &sourceMap{Sources: []string{"../src/app.js", "webpack:///./lib.js"}}
+	if got := sm.sourceURL(0, "https://example.com/static/app.min.js"); got != "https://example.com/src/app.js" {
+		t.Errorf("relative source = %q", got)
+	}
+	if got := sm.sourceURL(1, "https://example.com/static/app.min.js"); got != "webpack:///./lib.js" {
+		t.Errorf("absolute source = %q", got)
+	}
+	sm.SourceRoot = "https://cdn.example.com/src/"
+	if got := sm.sourceURL(0, ""); got != "https://cdn.example.com/src/../src/app.js" {
+		t.Errorf("source with root = %q", got)
+	}
+}
+
+func TestParseSourceMapErrors(t *testing.T) {
+	for _, data := range []string{
+		`not json`,
+		`{"version":3,"sections":[{"offset":{"line":0,"column":0},"map":{}}]}`,
+		`{"version":3,"sources":["a.js"],"mappings":"AA"}`,
+	} {
+		if _, err := parseSourceMap([]byte(data)); err == nil {
+			t.Errorf("parseSourceMap(%s): want error", data)
+		}
+	}
+}
+
+func TestMinifiedComments(t *testing.T) {
+	lines := []string{"x()", "//# sourceMappingURL=app.js.map", "//# debugId=D4A5B1B2-8C3E-4F6A-9B7D-1E2F3A4B5C6D", ""}
+	if got := sourceMappingURL(lines); got != "app.js.map" {
+		t.Errorf("sourceMappingURL = %q", got)
+	}
+	if got := debugIDComment(lines); got != "d4a5b1b2-8c3e-4f6a-9b7d-1e2f3a4b5c6d" {
+		t.Errorf("debugIDComment = %q", got)
+	}
+
+	data, err := dataURLSourceMap("data:application/json;charset=utf-8;base64,eyJ2ZXJzaW9uIjozfQ==")
+	if err != nil || string(data) != `{"version":3}` {
+		t.Errorf("base64 data URL = %q, %v", data, err)
+	}
+	if data, err := dataURLSourceMap("app.js.map"); data != nil || err != nil {
+		t.Errorf("plain URL = %q, %v", data, err)
+	}
+}
@@ -0,0 +1,77 @@
+// Package symbolicate resolves the frames of events with local debug
+// files, and fills in their source context from local sources, the way
+// Sentry would on ingestion.
+package symbolicate
+
+import (
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// Symbolicator resolves native frames with the ELF files found in its debug
+// directories, deobfuscates Java frames with ProGuard or R8 mapping files,
+// and unminifies JavaScript frames with source maps. Debug, mapping and
+// source map files are read once and cached.
+type Symbolicator struct {
+	DebugDirs  []string
+	Mappings   []string // mapping files, or directories of them
+	SourceMaps []string // source map directories, as [url-prefix=]dir
+
+	index         map[string]string // build ID to path
+	files         map[string]*debugFile
+	mappings      *mappingIndex
+	sourceMaps    *sourceMapIndex
+	minifiedFiles map[string]*minifiedFile // by URL and debug ID
+}
+
+// Stats counts the frames that Symbolicate resolved, and lists the images
+// that frames pointed into but no debug or mapping file was found for.
+type Stats struct {
+	Frames       int
+	Symbolicated int
+	Missing      []event.DebugImage
+}
+
+// Enabled reports whether the symbolicator has any debug directories,
+// mapping files or source maps to work with.
+func (s *Symbolicator) Enabled() bool {
+	return len(s.DebugDirs) > 0 || len(s.Mappings) > 0 || len(s.SourceMaps) > 0
+}
+
+// Symbolicate resolves the frames of an event's exceptions and threads
+// against its debug_meta images, using the debug directories, mapping
+// files and source maps that are configured.
+func (s *Symbolicator) Symbolicate(ev *event.Event) (Stats, error) {
+	var stats Stats
+	if ev.DebugMeta != nil && len(s.DebugDirs) > 0 {
+		if err := s.symbolicateNative(ev, &stats); err != nil {
+			return stats, err
+		}
+	}
+	if ev.DebugMeta != nil && len(s.Mappings) > 0 {
+		if err := s.deobfuscate(ev, &stats); err != nil {
+			return stats, err
+		}
+	}
+	if len(s.SourceMaps) > 0 {
+		if err := s.unminify(ev, &stats); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// stacktraces returns the stacktraces of the exceptions and threads.
+func stacktraces(ev *event.Event) []*event.Stacktrace {
+	var result []*event.Stacktrace
+	for i := range ev.Exception {
+		if st := ev.Exception[i].Stacktrace; st != nil {
+			result = append(result, st)
+		}
+	}
+	for i := range ev.Threads {
+		if st := ev.Threads[i].Stacktrace; st != nil {
+			result = append(result, st)
+		}
+	}
+	return result
+}
@@ -0,0 +1,24 @@
+#include <stdlib.h>
+
+volatile int *target;
+
+static inline __attribute__((always_inline)) void write_value(int value)
+{
+    *target = value;
+}
+
+static inline __attribute__((always_inline)) void trigger_crash(int value)
+{
+    write_value(value + 1);
+}
+
+__attribute__((noinline)) void run(int value)
+{
+    trigger_crash(value);
+}
+
+int main(int argc, char **argv)
+{
+    run(argc);
+    return 0;
+}
@@ -0,0 +1,24 @@
+namespace app {
+
+volatile int *target;
+
+class Writer {
+public:
+    static void write(int value)
+    {
+        *target = value;
+    }
+};
+
+__attribute__((noinline)) void run(int value)
+{
+    Writer::write(value + 1);
+}
+
+} // namespace app
+
+int main(int argc, char **argv)
+{
+    app::run(argc);
+    return 0;
+}
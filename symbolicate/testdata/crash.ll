@@ -0,0 +1,69 @@
+; ModuleID = 'crash.cpp'
+source_filename = "crash.cpp"
+target datalayout = "e-m:e-p270:32:32-p271:32:32-p272:64:64-i64:64-f80:128-n8:16:32:64-S128"
+target triple = "x86_64-pc-linux-gnu"
+
+@_ZN3app6targetE = dso_local global i32* null, align 8, !dbg !0
+
+define dso_local void @_ZN3app3runEi(i32 noundef %value) local_unnamed_addr #0 !dbg !20 {
+entry:
+  call void @llvm.dbg.value(metadata i32 %value, metadata !24, metadata !DIExpression()), !dbg !25
+  %add = add nsw i32 %value, 1, !dbg !26
+  call void @llvm.dbg.value(metadata i32 %add, metadata !27, metadata !DIExpression()), !dbg !29
+  %0 = load volatile i32*, i32** @_ZN3app6targetE, align 8, !dbg !31
+  store volatile i32 %add, i32* %0, align 4, !dbg !32
+  ret void, !dbg !33
+}
+
+define dso_local i32 @main(i32 noundef %argc, i8** nocapture noundef readnone %argv) local_unnamed_addr #1 !dbg !40 {
+entry:
+  tail call void @_ZN3app3runEi(i32 noundef %argc), !dbg !45
+  ret i32 0, !dbg !46
+}
+
+declare void @llvm.dbg.value(metadata, metadata, metadata) #2
+
+attributes #0 = { noinline nounwind uwtable "frame-pointer"="all" }
+attributes #1 = { nounwind uwtable "frame-pointer"="all" }
+attributes #2 = { nofree nosync nounwind readnone speculatable willreturn }
+
+!llvm.dbg.cu = !{!2}
+!llvm.module.flags = !{!50, !51, !52}
+
+!0 = !DIGlobalVariableExpression(var: !1, expr: !DIExpression())
+!1 = distinct !DIGlobalVariable(name: "target", linkageName: "_ZN3app6targetE", scope: !5, file: !3, line: 3, type: !6, isLocal: false, isDefinition: true)
+!2 = distinct !DICompileUnit(language: DW_LANG_C_plus_plus_14, file: !3, producer: "clang", isOptimized: true, runtimeVersion: 0, emissionKind: FullDebug, globals: !4, splitDebugInlining: false, nameTableKind: None)
+!3 = !DIFile(filename: "crash.cpp", directory: "/src")
+!4 = !{!0}
+!5 = !DINamespace(name: "app", scope: null)
+!6 = !DIDerivedType(tag: DW_TAG_pointer_type, baseType: !7, size: 64)
+!7 = !DIDerivedType(tag: DW_TAG_volatile_type, baseType: !8)
+!8 = !DIBasicType(name: "int", size: 32, encoding: DW_ATE_signed)
+!9 = distinct !DICompositeType(tag: DW_TAG_class_type, name: "Writer", scope: !5, file: !3, line: 5, size: 8, flags: DIFlagTypePassByValue, elements: !10, identifier: "_ZTSN3app6WriterE")
+!10 = !{!11}
+!11 = !DISubprogram(name: "write", linkageName: "_ZN3app6Writer5writeEi", scope: !9, file: !3, line: 7, type: !12, scopeLine: 7, flags: DIFlagPublic | DIFlagPrototyped | DIFlagStaticMember, spFlags: DISPFlagOptimized)
+!12 = !DISubroutineType(types: !13)
+!13 = !{null, !8}
+!20 = distinct !DISubprogram(name: "run", linkageName: "_ZN3app3runEi", scope: !5, file: !3, line: 13, type: !12, scopeLine: 14, flags: DIFlagPrototyped | DIFlagAllCallsDescribed, spFlags: DISPFlagDefinition | DISPFlagOptimized, unit: !2, retainedNodes: !23)
+!23 = !{!24}
+!24 = !DILocalVariable(name: "value", arg: 1, scope: !20, file: !3, line: 13, type: !8)
+!25 = !DILocation(line: 0, scope: !20)
+!26 = !DILocation(line: 15, column: 25, scope: !20)
+!27 = !DILocalVariable(name: "value", arg: 1, scope: !28, file: !3, line: 7, type: !8)
+!28 = distinct !DISubprogram(name: "write", linkageName: "_ZN3app6Writer5writeEi", scope: !9, file: !3, line: 7, type: !12, scopeLine: 8, flags: DIFlagPrototyped | DIFlagAllCallsDescribed, spFlags: DISPFlagDefinition | DISPFlagOptimized, unit: !2, declaration: !11, retainedNodes: !30)
+!29 = !DILocation(line: 0, scope: !28, inlinedAt: !34)
+!30 = !{!27}
+!31 = !DILocation(line: 9, column: 10, scope: !28, inlinedAt: !34)
+!32 = !DILocation(line: 9, column: 17, scope: !28, inlinedAt: !34)
+!33 = !DILocation(line: 16, column: 1, scope: !20)
+!34 = distinct !DILocation(line: 15, column: 5, scope: !20)
+!40 = distinct !DISubprogram(name: "main", scope: !3, file: !3, line: 20, type: !41, scopeLine: 21, flags: DIFlagPrototyped | DIFlagAllCallsDescribed, spFlags: DISPFlagDefinition | DISPFlagOptimized, unit: !2, retainedNodes: !44)
+!41 = !DISubroutineType(types: !42)
+!42 = !{!8, !8, !43}
+!43 = !DIDerivedType(tag: DW_TAG_pointer_type, baseType: null, size: 64)
+!44 = !{}
+!45 = !DILocation(line: 22, column: 5, scope: !40)
+!46 = !DILocation(line: 23, column: 5, scope: !40)
+!50 = !{i32 7, !"Dwarf Version", i32 5}
+!51 = !{i32 2, !"Debug Info Version", i32 3}
+!52 = !{i32 1, !"wchar_size", i32 4}
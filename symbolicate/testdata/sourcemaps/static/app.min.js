@@ -0,0 +1,3 @@
+function n(r){return"Hello, "+r}function t(){throw new Error("boom")}export function run(){n("world");t()}
+//# debugId=d4a5b1b2-8c3e-4f6a-9b7d-1e2f3a4b5c6d
+//# sourceMappingURL=app.min.js.map
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getsentry/slope/envelope"
+)
+
+func TestSymbolicate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "crash.envelope")
+	out := filepath.Join(dir, "out.envelope")
+	ev := `{"exception":{"values":[{"stacktrace":{"frames":[{"instruction_addr":"0x1114a"}]}}]},` +
+		`"debug_meta":{"images":[{"type":"elf","code_file":"/usr/bin/crash",` +
+		`"code_id":"89a79abce0b8c4c8e628e26bc0b8e10f38a90b1a","image_addr":"0x10000","image_size":16384}]}}`
+	data := fmt.Sprintf("{}\n{\"type\":\"event\",\"length\":%d}\n%s\n", len(ev), ev)
+	os.WriteFile(path, []byte(data), 0o644)
+
+	var w bytes.Buffer
+	if status := symbolicateEnvelope(&w, []string{path}); status != 1 {
+		t.Errorf("without --debug-dir: status = %d, want 1", status)
+	}
+
+	w.Reset()
+	status := symbolicateEnvelope(&w, []string{"--debug-dir", "symbolicate/testdata", "-o", out, path})
+	if status != 0 {
+		t.Fatalf("status = %d, want 0, output:\n%s", status, w.String())
+	}
+	if got := strings.TrimSpace(w.String()); got != "symbolicated 1 of 1 frames" {
+		t.Errorf("output = %q", got)
+	}
+	if unchanged, _ := os.ReadFile(path); string(unchanged) != data {
+		t.Errorf("input file was modified")
+	}
+
+	env, _, _, err := readEnvelope(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	symbolicated, err := env.Items[0].Event()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var functions []string
+	for _, f := range symbolicated.Exception[0].Stacktrace.Frames {
+		functions = append(functions, fmt.Sprintf("%s:%d", f.Function, f.Lineno))
+	}
+	if got := strings.Join(functions, " "); got != "run:17 trigger_crash:12 write_value:7" {
+		t.Errorf("frames = %s", got)
+	}
+	if findings := envelope.Validate(env); len(findings) != 0 {
+		t.Errorf("findings = %v", findings)
+	}
+}
+
+func TestSymbolicateMapping(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "java.envelope")
+	ev := `{"exception":{"values":[{"type":"b","module":"a","stacktrace":{"frames":[{"module":"a.a","function":"b","lineno":3}]}}]},` +
+		`"debug_meta":{"images":[{"type":"proguard","uuid":"ea338d3c-b49c-5411-a9b6-9aabb39e062a"},{"type":"proguard","uuid":"00000000-0000-0000-0000-000000000000"}]}}`
+	os.WriteFile(path, []byte(fmt.Sprintf("{}\n{\"type\":\"event\",\"length\":%d}\n%s\n", len(ev), ev)), 0o644)
+
+	var w bytes.Buffer
+	if status := symbolicateEnvelope(&w, []string{"--mapping", "symbolicate/testdata/mapping.txt", path}); status != 0 {
+		t.Fatalf("status = %d, want 0, output:\n%s", status, w.String())
+	}
+	want := path + ": item 1: no debug file for proguard 00000000-0000-0000-0000-000000000000\nsymbolicated 1 of 1 frames\n"
+	if w.String() != want {
+		t.Errorf("output = %q, want %q", w.String(), want)
+	}
+
+	env, _, _, err := readEnvelope(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deobfuscated, err := env.Items[0].Event()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ex := deobfuscated.Exception[0]
+	if f := ex.Stacktrace.Frames[0]; ex.Type != "Crasher$CrashException" || f.Module != "io.sentry.sample.Crasher" || f.Function != "throwNested" || f.Lineno != 21 {
+		t.Errorf("exception %s, frame %s.%s:%d", ex.Type, f.Module, f.Function, f.Lineno)
+	}
+}
+
+func TestSymbolicateSourceMaps(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "browser.envelope")
+	ev := `{"platform":"javascript","exception":{"values":[{"type":"Error","stacktrace":{"frames":[` +
+		`{"function":"t","abs_path":"https://example.com/static/app.min.js","lineno":1,"colno":46},` +
+		`{"function":"f","abs_path":"https://example.com/vendor.min.js","lineno":1,"colno":1}]}}]}}`
+	os.WriteFile(path, []byte(fmt.Sprintf("{}\n{\"type\":\"event\",\"length\":%d}\n%s\n", len(ev), ev)), 0o644)
+
+	var w bytes.Buffer
+	if status := symbolicateEnvelope(&w, []string{"--source-maps", "https://example.com/static=symbolicate/testdata/sourcemaps/static", path}); status != 0 {
+		t.Fatalf("status = %d, want 0, output:\n%s", status, w.String())
+	}
+	if got := strings.TrimSpace(w.String()); got != "symbolicated 1 of 1 frames" {
+		t.Errorf("output = %q", got)
+	}
+
+	env, _, _, err := readEnvelope(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unminified, err := env.Items[0].Event()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := unminified.Exception[0].Stacktrace.Frames[0]
+	if f.Function != "crash" || f.Filename != "src/app.js" || f.Lineno != 6 || f.Colno != 3 || f.ContextLine != `  throw new Error("boom");` {
+		t.Errorf("frame = %s (%s:%d:%d) %q", f.Function, f.Filename, f.Lineno, f.Colno, f.ContextLine)
+	}
+	if findings := envelope.Validate(env); len(findings) != 0 {
+		t.Errorf("findings = %v", findings)
+	}
+}
+
+func TestSymbolicateDamaged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "damaged.envelope")
+	data := "{}\n{\"type\":\"event\",\"length\":100}\n{}\n"
+	os.WriteFile(path, []byte(data), 0o644)
+
+	var w bytes.Buffer
+	if status := symbolicateEnvelope(&w, []string{"--debug-dir", "symbolicate/testdata", path}); status != 1 {
+		t.Errorf("status = %d, want 1", status)
+	}
+	if !strings.Contains(w.String(), "error:") {
+		t.Errorf("output = %q", w.String())
+	}
+	if unchanged, _ := os.ReadFile(path); string(unchanged) != data {
+		t.Errorf("damaged file was written back:\n%s", unchanged)
+	}
+}
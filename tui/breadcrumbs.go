@@ -0,0 +1,209 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	lipgloss "charm.land/lipgloss/v2"
+	"github.com/getsentry/slope/envelope"
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// Breadcrumb levels from least to most severe.
+var breadcrumbLevels = []string{"debug", "info", "warning", "error", "fatal"}
+
+// breadcrumbFilter selects breadcrumbs by category and minimum level. Empty
+// values match everything.
+type breadcrumbFilter struct {
+	category string
+	level    string
+}
+
+func (f breadcrumbFilter) match(b event.Breadcrumb) bool {
+	if f.category != "" && b.Category != f.category {
+		return false
+	}
+	return f.level == "" || levelRank(breadcrumbLevel(b)) >= levelRank(f.level)
+}
+
+func (f breadcrumbFilter) String() string {
+	var parts []string
+	if f.category != "" {
+		parts = append(parts, "category "+f.category)
+	}
+	if f.level != "" {
+		parts = append(parts, "level ≥ "+f.level)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// filterBreadcrumbs returns the indices of the matching breadcrumbs.
+func filterBreadcrumbs(crumbs []event.Breadcrumb, f breadcrumbFilter) []int {
+	var indices []int
+	for i, b := range crumbs {
+		if f.match(b) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// nextCategory cycles through the categories present, then back to all.
+func nextCategory(crumbs []event.Breadcrumb, category string) string {
+	var categories []string
+	for _, b := range crumbs {
+		if b.Category != "" && !slices.Contains(categories, b.Category) {
+			categories = append(categories, b.Category)
+		}
+	}
+	slices.Sort(categories)
+	i := slices.Index(categories, category)
+	if i+1 < len(categories) {
+		return categories[i+1]
+	}
+	return ""
+}
+
+// nextLevel cycles the minimum level from info up to fatal, then back to all.
+func nextLevel(level string) string {
+	i := slices.Index(breadcrumbLevels, level)
+	switch {
+	case level == "":
+		return breadcrumbLevels[1]
+	case i+1 < len(breadcrumbLevels):
+		return breadcrumbLevels[i+1]
+	}
+	return ""
+}
+
+func breadcrumbLevel(b event.Breadcrumb) string {
+	if b.Level == "" {
+		return "info"
+	}
+	return b.Level
+}
+
+func levelRank(level string) int {
+	if level == "critical" {
+		level = "fatal"
+	}
+	if i := slices.Index(breadcrumbLevels, level); i >= 0 {
+		return i
+	}
+	return slices.Index(breadcrumbLevels, "info")
+}
+
+func levelStyle(level string) lipgloss.Style {
+	switch levelRank(level) {
+	case 0:
+		return helpStyle
+	case 2:
+		return warningStyle
+	case 3, 4:
+		return errorStyle
+	}
+	return lipgloss.NewStyle()
+}
+
+// formatBreadcrumbs renders the given breadcrumbs as a timeline, with times
+// relative to the event, or else to the first breadcrumb. It also returns
+// the line of the breadcrumb at cursor.
+func formatBreadcrumbs(ev *event.Event, indices []int, cursor int, expanded map[int]bool) (string, int) {
+	if len(indices) == 0 {
+		return helpStyle.Render("(no breadcrumbs)"), 0
+	}
+	crumbs := ev.Breadcrumbs
+	base := ev.Timestamp.Time
+	if base.IsZero() {
+		base = crumbs[indices[0]].Timestamp.Time
+	}
+
+	var relWidth, categoryWidth int
+	for _, i := range indices {
+		relWidth = max(relWidth, len(relativeTime(crumbs[i].Timestamp.Time, base)))
+		categoryWidth = max(categoryWidth, len(crumbs[i].Category))
+	}
+	categoryWidth = min(categoryWidth, 24)
+
+	var b strings.Builder
+	var line, cursorLine int
+	for n, i := range indices {
+		crumb := crumbs[i]
+		prefix := "  "
+		if n == cursor {
+			prefix = "> "
+			cursorLine = line
+		}
+		level := breadcrumbLevel(crumb)
+		parts := []string{
+			fmt.Sprintf("%*s", relWidth, relativeTime(crumb.Timestamp.Time, base)),
+			helpStyle.Render(absoluteTime(crumb.Timestamp.Time)),
+			levelStyle(level).Render(fmt.Sprintf("%-7s", level)),
+			labelStyle.Render(fmt.Sprintf("%-*s", categoryWidth, crumb.Category)),
+			breadcrumbMessage(crumb),
+		}
+		if len(crumb.Data) > 0 && !expanded[i] {
+			parts = append(parts, helpStyle.Render("{…}"))
+		}
+		b.WriteString(prefix + strings.Join(parts, "  ") + "\n")
+		line++
+
+		if len(crumb.Data) > 0 && expanded[i] {
+			data, _ := json.Marshal(crumb.Data)
+			for _, l := range strings.Split(highlightJSON(envelope.PrettyJSON(data)), "\n") {
+				b.WriteString("      " + l + "\n")
+				line++
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), cursorLine
+}
+
+// breadcrumbMessage returns the message, or a summary of well-known data
+// for breadcrumbs without one.
+func breadcrumbMessage(b event.Breadcrumb) string {
+	if b.Message != "" {
+		return b.Message
+	}
+	if b.Type == "http" {
+		var parts []string
+		for _, key := range []string{"method", "url"} {
+			if v, ok := b.Data[key]; ok {
+				parts = append(parts, fmt.Sprint(v))
+			}
+		}
+		if v, ok := b.Data["status_code"]; ok {
+			parts = append(parts, fmt.Sprintf("[%v]", v))
+		}
+		return strings.Join(parts, " ")
+	}
+	if b.Type == "navigation" {
+		from, _ := b.Data["from"].(string)
+		to, _ := b.Data["to"].(string)
+		if from != "" || to != "" {
+			return from + " → " + to
+		}
+	}
+	return ""
+}
+
+func relativeTime(t, base time.Time) string {
+	if t.IsZero() {
+		return "?"
+	}
+	d := t.Sub(base).Round(time.Millisecond)
+	if d > 0 {
+		return "+" + d.String()
+	}
+	return d.String()
+}
+
+func absoluteTime(t time.Time) string {
+	if t.IsZero() {
+		return fmt.Sprintf("%-23s", "")
+	}
+	return t.UTC().Format("2006-01-02 15:04:05.000")
+}
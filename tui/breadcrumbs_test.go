@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+const breadcrumbEvent = `{"timestamp":"2025-10-09T08:05:05Z","breadcrumbs":{"values":[` +
+	`{"timestamp":"2025-10-09T08:05:01.500Z","category":"ui.click","message":"button","level":"info"},` +
+	`{"timestamp":"2025-10-09T08:05:03Z","type":"http","category":"http","data":{"method":"GET","url":"/api","status_code":500},"level":"error"},` +
+	`{"timestamp":"2025-10-09T08:05:04.750Z","category":"log","message":"retrying","level":"debug"}]}}`
+
+func TestFormatBreadcrumbs(t *testing.T) {
+	ev := parseEvent(t, breadcrumbEvent)
+	content, line := formatBreadcrumbs(ev, []int{0, 1, 2}, 1, map[int]bool{})
+	got := ansi.Strip(content)
+	want := "   -3.5s  2025-10-09 08:05:01.500  info     ui.click  button\n" +
+		">    -2s  2025-10-09 08:05:03.000  error    http      GET /api [500]  {…}\n" +
+		"  -250ms  2025-10-09 08:05:04.750  debug    log       retrying"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if line != 1 {
+		t.Errorf("cursor line = %d, want 1", line)
+	}
+
+	content, line = formatBreadcrumbs(ev, []int{1, 2}, 1, map[int]bool{1: true})
+	got = ansi.Strip(content)
+	if !strings.Contains(got, `"status_code": 500`) || strings.Contains(got, "{…}") {
+		t.Errorf("expanded data missing, got:\n%s", got)
+	}
+	if lines := strings.Split(got, "\n"); !strings.HasPrefix(lines[line], "> ") || !strings.Contains(lines[line], "retrying") {
+		t.Errorf("cursor line %d = %q", line, lines[line])
+	}
+
+	if content, _ := formatBreadcrumbs(ev, nil, 0, nil); !strings.Contains(content, "no breadcrumbs") {
+		t.Errorf("got %q for no breadcrumbs", content)
+	}
+}
+
+func TestFilterBreadcrumbs(t *testing.T) {
+	crumbs := parseEvent(t, breadcrumbEvent).Breadcrumbs
+	tests := []struct {
+		filter breadcrumbFilter
+		want   []int
+	}{
+		{breadcrumbFilter{}, []int{0, 1, 2}},
+		{breadcrumbFilter{category: "http"}, []int{1}},
+		{breadcrumbFilter{level: "info"}, []int{0, 1}},
+		{breadcrumbFilter{level: "error"}, []int{1}},
+		{breadcrumbFilter{category: "log", level: "info"}, nil},
+	}
+	for _, tt := range tests {
+		if got := filterBreadcrumbs(crumbs, tt.filter); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestBreadcrumbFilterCycle(t *testing.T) {
+	crumbs := parseEvent(t, breadcrumbEvent).Breadcrumbs
+	var categories []string
+	for c := nextCategory(crumbs, ""); c != ""; c = nextCategory(crumbs, c) {
+		categories = append(categories, c)
+	}
+	if want := []string{"http", "log", "ui.click"}; !reflect.DeepEqual(categories, want) {
+		t.Errorf("categories = %v, want %v", categories, want)
+	}
+
+	var levels []string
+	for l := nextLevel(""); l != ""; l = nextLevel(l) {
+		levels = append(levels, l)
+	}
+	if want := []string{"info", "warning", "error", "fatal"}; !reflect.DeepEqual(levels, want) {
+		t.Errorf("levels = %v, want %v", levels, want)
+	}
+}
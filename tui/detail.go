@@ -0,0 +1,314 @@
+package tui
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"github.com/getsentry/slope/envelope"
+	"github.com/getsentry/slope/envelope/event"
+	"github.com/getsentry/slope/envelope/minidump"
+)
+
+type detailKind int
+
+const (
+	detailStacktrace detailKind = iota
+	detailBreadcrumbs
+	detailSpans
+	detailMinidump
+	detailThreads
+	detailImages
+)
+
+func (k detailKind) String() string {
+	switch k {
+	case detailStacktrace:
+		return "Stacktrace"
+	case detailBreadcrumbs:
+		return "Breadcrumbs"
+	case detailSpans:
+		return "Spans"
+	case detailMinidump:
+		return "Minidump"
+	case detailThreads:
+		return "Threads"
+	case detailImages:
+		return "Images"
+	}
+	return ""
+}
+
+// detailView shows the selected item in a scrollable view, rendered
+// according to its kind or as raw JSON, or hex for binary items.
+type detailView struct {
+	kind     detailKind
+	kinds    []detailKind
+	event    *event.Event
+	spans    []event.Span
+	minidump *minidump.Minidump
+	raw      bool
+	viewport viewport.Model
+
+	// Breadcrumbs
+	filter   breadcrumbFilter
+	cursor   int
+	expanded map[int]bool
+
+	// Minidump threads
+	thread int
+
+	// Debug images
+	debugFiles []debugFileStatus
+}
+
+// selectedDetail decodes the selected item for the detail views. It
+// reports false if there is no view for the item.
+func (m Model) selectedDetail() (detailView, bool) {
+	item := m.envelope.Items[m.selected]
+	var d detailView
+	switch item.Type {
+	case "event", "transaction":
+		ev, err := item.Event()
+		if err != nil {
+			return d, false
+		}
+		d.event = ev
+		if root := ev.RootSpan(); root != nil {
+			d.spans = append([]event.Span{*root}, ev.Spans...)
+		}
+	case "span":
+		spans, err := event.ParseSpans(item.Payload)
+		if err != nil {
+			return d, false
+		}
+		d.spans = spans
+	case "attachment":
+		if !item.IsMinidump() {
+			return d, false
+		}
+		md, err := item.Minidump()
+		if err != nil {
+			return d, false
+		}
+		d.minidump = md
+		d.kinds = append(d.kinds, detailMinidump)
+		if len(md.Threads) > 0 {
+			d.kinds = append(d.kinds, detailThreads)
+			d.thread = max(crashedThread(md), 0)
+		}
+	}
+
+	if len(d.spans) > 0 {
+		d.kinds = append(d.kinds, detailSpans)
+	}
+	if d.event != nil && hasStacktrace(d.event) {
+		d.kinds = append(d.kinds, detailStacktrace)
+	}
+	if d.event != nil && len(d.event.Breadcrumbs) > 0 {
+		d.kinds = append(d.kinds, detailBreadcrumbs)
+	}
+	if d.event != nil && d.event.DebugMeta != nil && len(d.event.DebugMeta.Images) > 0 {
+		d.kinds = append(d.kinds, detailImages)
+		d.debugFiles = m.lookupDebugFiles(d.event.DebugMeta.Images)
+	}
+	return d, len(d.kinds) > 0
+}
+
+func (m Model) openDetail(d detailView) (tea.Model, tea.Cmd) {
+	d.kind = d.kinds[0]
+	d.viewport = viewport.New()
+	d.expanded = map[int]bool{}
+	m.detail = d
+	m.mode = modeDetail
+	m.refreshDetail()
+	return m, nil
+}
+
+func (m *Model) refreshDetail() {
+	width, height := m.width, m.height
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 24
+	}
+	// Leave room for the title, message and help lines
+	vp := &m.detail.viewport
+	vp.SetWidth(width)
+	vp.SetHeight(max(height-5, 1))
+	content, line := m.detailContent()
+	vp.SetContent(content)
+	if m.detail.kind == detailBreadcrumbs && !m.detail.raw {
+		switch {
+		case line < vp.YOffset():
+			vp.SetYOffset(line)
+		case line >= vp.YOffset()+vp.Height():
+			vp.SetYOffset(line - vp.Height() + 1)
+		}
+	}
+}
+
+// detailContent renders the view and returns the line of the cursor, if
+// any.
+func (m Model) detailContent() (string, int) {
+	if m.detail.raw {
+		payload := m.envelope.Items[m.selected].Payload
+		if m.detail.minidump != nil {
+			return hex.Dump(payload), 0
+		}
+		return highlightJSON(envelope.PrettyJSON(json.RawMessage(payload))), 0
+	}
+	switch m.detail.kind {
+	case detailStacktrace:
+		return formatStacktrace(m.detail.event), 0
+	case detailBreadcrumbs:
+		return formatBreadcrumbs(m.detail.event, m.breadcrumbIndices(), m.detail.cursor, m.detail.expanded)
+	case detailSpans:
+		return formatWaterfall(m.detail.spans, m.detail.viewport.Width()), 0
+	case detailMinidump:
+		return formatMinidump(m.detail.minidump), 0
+	case detailThreads:
+		return formatMinidumpThread(m.detail.minidump, &m.detail.minidump.Threads[m.detail.thread]), 0
+	case detailImages:
+		return formatDebugImages(m.detail.event.DebugMeta.Images, m.detail.debugFiles), 0
+	}
+	return "", 0
+}
+
+func (m Model) breadcrumbIndices() []int {
+	return filterBreadcrumbs(m.detail.event.Breadcrumbs, m.detail.filter)
+}
+
+func (m Model) detailTitle() string {
+	title := itemLabel(m.selected, m.envelope.Items[m.selected])
+	if m.detail.raw {
+		return title + " · " + m.rawLabel()
+	}
+	title += " · " + m.detail.kind.String()
+	if m.detail.kind == detailBreadcrumbs {
+		shown, total := len(m.breadcrumbIndices()), len(m.detail.event.Breadcrumbs)
+		if f := m.detail.filter.String(); f != "" {
+			title += " · " + f
+		}
+		title += fmt.Sprintf(" · %d/%d", shown, total)
+	}
+	if m.detail.kind == detailThreads {
+		threads := m.detail.minidump.Threads
+		title += fmt.Sprintf(" · %d · %d/%d", threads[m.detail.thread].ID, m.detail.thread+1, len(threads))
+	}
+	return title
+}
+
+func (m Model) updateDetail(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case keyEsc, keyQ:
+		m.mode = modeList
+		return m, nil
+	case keyR:
+		m.detail.raw = !m.detail.raw
+		m.refreshDetail()
+		m.detail.viewport.GotoTop()
+		return m, nil
+	case keyTab:
+		if kinds := m.detail.kinds; len(kinds) > 1 {
+			i := slices.Index(kinds, m.detail.kind)
+			m.detail.kind = kinds[(i+1)%len(kinds)]
+			m.detail.raw = false
+			m.refreshDetail()
+			m.detail.viewport.GotoTop()
+		}
+		return m, nil
+	}
+	if m.detail.kind == detailBreadcrumbs && !m.detail.raw {
+		if next, ok := m.updateBreadcrumbs(msg); ok {
+			next.refreshDetail()
+			return next, nil
+		}
+	}
+	if m.detail.kind == detailThreads && !m.detail.raw {
+		if next, ok := m.updateThreads(msg); ok {
+			next.refreshDetail()
+			next.detail.viewport.GotoTop()
+			return next, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.detail.viewport, cmd = m.detail.viewport.Update(msg)
+	return m, cmd
+}
+
+// updateBreadcrumbs handles the keys of the breadcrumbs view and reports
+// whether the key was handled.
+func (m Model) updateBreadcrumbs(msg tea.KeyPressMsg) (Model, bool) {
+	count := len(m.breadcrumbIndices())
+	switch msg.String() {
+	case keyUp, keyK:
+		if m.detail.cursor > 0 {
+			m.detail.cursor--
+		}
+	case keyDown, keyJ:
+		if m.detail.cursor < count-1 {
+			m.detail.cursor++
+		}
+	case keyEnter:
+		if count > 0 {
+			i := m.breadcrumbIndices()[m.detail.cursor]
+			m.detail.expanded[i] = !m.detail.expanded[i]
+		}
+	case keyC:
+		m.detail.filter.category = nextCategory(m.detail.event.Breadcrumbs, m.detail.filter.category)
+		m.detail.cursor = 0
+	case keyV:
+		m.detail.filter.level = nextLevel(m.detail.filter.level)
+		m.detail.cursor = 0
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+// updateThreads switches between the threads of a minidump and reports
+// whether the key was handled.
+func (m Model) updateThreads(msg tea.KeyPressMsg) (Model, bool) {
+	count := len(m.detail.minidump.Threads)
+	switch msg.String() {
+	case keyN:
+		m.detail.thread = (m.detail.thread + 1) % count
+	case keyP:
+		m.detail.thread = (m.detail.thread + count - 1) % count
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+func (m Model) detailHelp() string {
+	if m.detail.raw {
+		return "↑/↓ scroll · r formatted · esc back"
+	}
+	help := "↑/↓ scroll"
+	if m.detail.kind == detailBreadcrumbs {
+		help = "↑/↓ select · enter data · c category · v level"
+	}
+	if m.detail.kind == detailThreads {
+		help += " · n/p thread"
+	}
+	if len(m.detail.kinds) > 1 {
+		help += " · tab next view"
+	}
+	return help + " · r raw " + strings.ToLower(m.rawLabel()) + " · esc back"
+}
+
+// rawLabel names the raw form of the item: hex for binary items, JSON
+// otherwise.
+func (m Model) rawLabel() string {
+	if m.detail.minidump != nil {
+		return "Hex"
+	}
+	return "JSON"
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	lipgloss "charm.land/lipgloss/v2"
 	"github.com/charmbracelet/x/term"
 	"github.com/getsentry/slope/envelope"
 )
@@ -16,6 +17,9 @@ func itemLabel(idx int, item envelope.Item) string {
 	if item.Filename != "" {
 		parts = append(parts, item.Filename)
 	}
+	if item.Broken() {
+		parts = append(parts, "BROKEN")
+	}
 	return strings.Join(parts, " · ")
 }
 
@@ -42,3 +46,10 @@ func formatSize(b int) string {
 		return fmt.Sprintf("%d B", b)
 	}
 }
+
+func severityStyle(s envelope.Severity) lipgloss.Style {
+	if s == envelope.SeverityError {
+		return errorStyle
+	}
+	return warningStyle
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// debugFileStatus is the debug file found for an image, if looked up.
+type debugFileStatus struct {
+	path string
+	err  error
+}
+
+// lookupDebugFiles looks up the debug file of each image in the debug
+// directories, or returns nil if there are none to search.
+func (m Model) lookupDebugFiles(images []event.DebugImage) []debugFileStatus {
+	if m.symbolicator == nil || !m.symbolicator.Enabled() {
+		return nil
+	}
+	statuses := make([]debugFileStatus, len(images))
+	for i, img := range images {
+		statuses[i].path, statuses[i].err = m.symbolicator.Locate(img)
+	}
+	return statuses
+}
+
+// formatDebugImages lists the images of an event with their IDs, and
+// whether a debug file was found for each, if looked up.
+func formatDebugImages(images []event.DebugImage, statuses []debugFileStatus) string {
+	var b strings.Builder
+	title := fmt.Sprintf("Images (%d)", len(images))
+	if statuses != nil {
+		missing := 0
+		for _, s := range statuses {
+			if s.path == "" {
+				missing++
+			}
+		}
+		title += fmt.Sprintf(" · %d without debug files", missing)
+	}
+	b.WriteString(labelStyle.Render(title) + "\n")
+	if statuses == nil {
+		b.WriteString(helpStyle.Render("Start slope with --debug-dir, --mapping or --source-maps to look for debug files") + "\n")
+	}
+
+	nameWidth := 0
+	for _, img := range images {
+		nameWidth = max(nameWidth, len(imageName(img)))
+	}
+	for i, img := range images {
+		marker := " "
+		if statuses != nil {
+			marker = savedStyle.Render("✓")
+			if statuses[i].path == "" {
+				marker = errorStyle.Render("✗")
+			}
+		}
+		details := []string{img.Type, img.Arch}
+		line := fmt.Sprintf("\n%s %-*s  %s %9s", marker, nameWidth, imageName(img), addrStyle.Render(fmt.Sprintf("%18s", img.ImageAddr)), formatSize(int(img.ImageSize)))
+		b.WriteString(line + "  " + helpStyle.Render(strings.Join(nonEmpty(details), " · ")) + "\n")
+
+		for _, f := range []summaryField{
+			{"code file", img.CodeFile},
+			{"code id", img.CodeID},
+			{"debug file", img.DebugFile},
+			{"debug id", img.DebugID},
+			{"uuid", img.UUID},
+		} {
+			if f.value != "" {
+				b.WriteString(fmt.Sprintf("    %s %s\n", helpStyle.Render(fmt.Sprintf("%-10s", f.label)), f.value))
+			}
+		}
+		if statuses != nil {
+			switch s := statuses[i]; {
+			case s.err != nil:
+				b.WriteString("    " + errorStyle.Render(s.err.Error()) + "\n")
+			case s.path == "":
+				b.WriteString("    " + errorStyle.Render("no debug file") + "\n")
+			default:
+				b.WriteString("    " + savedStyle.Render("→ "+s.path) + "\n")
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// imageName names an image by its code file, or its debug file if it has
+// none. ProGuard images are named by their UUID.
+func imageName(img event.DebugImage) string {
+	if img.Type == "proguard" && img.UUID != "" {
+		return "proguard " + img.UUID
+	}
+	if img.CodeFile != "" {
+		return moduleBase(img.CodeFile)
+	}
+	if img.DebugFile != "" {
+		return moduleBase(img.DebugFile)
+	}
+	return img.Type
+}
+
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
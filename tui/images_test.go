@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/getsentry/slope/envelope"
+	"github.com/getsentry/slope/envelope/event"
+	"github.com/getsentry/slope/symbolicate"
+)
+
+var testImages = []event.DebugImage{
+	{Type: "elf", CodeFile: "/usr/bin/crash", CodeID: "89a79abce0b8c4c8e628e26bc0b8e10f38a90b1a", Arch: "x86_64", ImageAddr: "0x10000", ImageSize: 16384},
+	{Type: "elf", CodeFile: "/usr/lib/libc.so.6", DebugID: "33221100-5544-7766-8899-aabbccddeeff", ImageAddr: "0x7f0000000000", ImageSize: 65536},
+}
+
+func TestFormatDebugImages(t *testing.T) {
+	got := ansi.Strip(formatDebugImages(testImages, nil))
+	for _, want := range []string{
+		"Images (2)\n",
+		"--debug-dir",
+		"crash                 0x10000   16.0 KB  elf · x86_64",
+		"    code id    89a79abce0b8c4c8e628e26bc0b8e10f38a90b1a",
+		"libc.so.6      0x7f0000000000   64.0 KB  elf",
+		"    debug id   33221100-5544-7766-8899-aabbccddeeff",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "no debug file") {
+		t.Errorf("unexpected status without lookup:\n%s", got)
+	}
+
+	got = ansi.Strip(formatDebugImages(testImages, []debugFileStatus{{path: "/debug/crash"}, {}}))
+	for _, want := range []string{
+		"Images (2) · 1 without debug files",
+		"✓ crash",
+		"    → /debug/crash",
+		"✗ libc.so.6",
+		"    no debug file",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+
+	got = ansi.Strip(formatDebugImages(testImages[:1], []debugFileStatus{{err: errors.New("permission denied")}}))
+	if !strings.Contains(got, "    permission denied") {
+		t.Errorf("missing error in:\n%s", got)
+	}
+}
+
+func TestDetailImages(t *testing.T) {
+	data, _ := json.Marshal(map[string]any{"debug_meta": map[string]any{"images": testImages}})
+	m := testModel(0)
+	m.envelope.Add(envelope.Item{
+		Header:  json.RawMessage(`{"type":"event"}`),
+		Payload: data,
+		Type:    "event",
+	})
+	m.SetSymbolicator(&symbolicate.Symbolicator{DebugDirs: []string{"../symbolicate/testdata"}})
+
+	m = update(m, specialKey(tea.KeyEnter))
+	if m.detail.kind != detailImages {
+		t.Fatalf("kind = %v, want images", m.detail.kind)
+	}
+	view := ansi.Strip(viewText(m))
+	for _, want := range []string{"Images (2) · 1 without debug files", "→ ../symbolicate/testdata/crash", "no debug file"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("missing %q in:\n%s", want, view)
+		}
+	}
+}
@@ -7,10 +7,17 @@ const (
 	keyJ     = "j"
 	keyEnter = "enter"
 	keyEsc   = "esc"
+	keyTab   = "tab"
 	keyQ     = "q"
+	keyR     = "r"
+	keyS     = "s"
 	keyD     = "d"
 	keyA     = "a"
+	keyC     = "c"
 	keyE     = "e"
+	keyN     = "n"
+	keyP     = "p"
+	keyV     = "v"
 	keyW     = "w"
 	keyX     = "x"
 	keyY     = "y"
@@ -0,0 +1,297 @@
+package tui
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/getsentry/slope/envelope/minidump"
+)
+
+// formatMinidump renders the exception, system info, threads, modules,
+// annotations and streams of a minidump.
+func formatMinidump(md *minidump.Minidump) string {
+	var b strings.Builder
+	var platform minidump.Platform
+	if md.SystemInfo != nil {
+		platform = md.SystemInfo.Platform
+	}
+
+	if ex := md.Exception; ex != nil {
+		b.WriteString(labelStyle.Render(minidumpExceptionTitle(md, ex, platform)) + "\n")
+		details := []string{fmt.Sprintf("thread %d", ex.ThreadID)}
+		if len(ex.Parameters) > 0 {
+			params := make([]string, len(ex.Parameters))
+			for i, p := range ex.Parameters {
+				params[i] = fmt.Sprintf("0x%x", p)
+			}
+			details = append(details, "parameters "+strings.Join(params, ", "))
+		}
+		b.WriteString("  " + helpStyle.Render(strings.Join(details, " · ")) + "\n")
+	}
+	for _, err := range md.Errors {
+		b.WriteString(errorStyle.Render(err.Error()) + "\n")
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(labelStyle.Render("System") + "\n")
+	var osName, cpu string
+	if info := md.SystemInfo; info != nil {
+		osName = strings.TrimSpace(info.Platform.String() + " " + info.OSVersion() + " " + info.CSDVersion)
+		cpu = info.Arch.String()
+		if info.Processors > 0 {
+			cpu += fmt.Sprintf(" · %d processors", info.Processors)
+		}
+		if info.CPUVendor != "" {
+			cpu += " · " + info.CPUVendor
+		}
+	}
+	b.WriteString(formatFields([]summaryField{
+		{"os", osName},
+		{"cpu", cpu},
+		{"written", absoluteTime(md.Header.Time())},
+	}))
+
+	if len(md.Threads) > 0 {
+		b.WriteString("\n" + labelStyle.Render(fmt.Sprintf("Threads (%d)", len(md.Threads))) + "\n")
+		b.WriteString(formatMinidumpThreads(md))
+	}
+	if len(md.Modules) > 0 {
+		b.WriteString("\n" + labelStyle.Render(fmt.Sprintf("Modules (%d)", len(md.Modules))) + "\n")
+		b.WriteString(formatModules(md.Modules))
+	}
+	if md.Crashpad != nil {
+		b.WriteString("\n" + labelStyle.Render("Annotations") + "\n")
+		b.WriteString(formatAnnotations(md))
+	}
+
+	b.WriteString("\n" + labelStyle.Render(fmt.Sprintf("Streams (%d)", len(md.Streams))) + "\n")
+	for _, s := range md.Streams {
+		if s.Type == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %-20s %10s  %s\n", s.Type, formatSize(int(s.Size)), helpStyle.Render(fmt.Sprintf("@ 0x%x", s.RVA))))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func minidumpExceptionTitle(md *minidump.Minidump, ex *minidump.Exception, platform minidump.Platform) string {
+	title := fmt.Sprintf("0x%08x", ex.Code)
+	if name := ex.Name(platform); name != "" {
+		title = fmt.Sprintf("%s (%s)", name, title)
+	}
+	title += fmt.Sprintf(" at 0x%x", ex.Address)
+	if m := md.ModuleAt(ex.Address); m != nil {
+		title += " in " + moduleBase(m.Name)
+	}
+	return title
+}
+
+func formatMinidumpThreads(md *minidump.Minidump) string {
+	var b strings.Builder
+	for _, t := range md.Threads {
+		line := fmt.Sprintf("  %-8d", t.ID)
+		if md.Exception != nil && md.Exception.ThreadID == t.ID {
+			line += " " + errorStyle.Render("crashed")
+		}
+		if t.Name != "" {
+			line += " " + t.Name
+		}
+		line += " " + helpStyle.Render(fmt.Sprintf("stack 0x%x · %s", t.Stack.Start, formatSize(int(t.Stack.Size))))
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func formatModules(modules []minidump.Module) string {
+	nameWidth := 0
+	for _, m := range modules {
+		nameWidth = max(nameWidth, len(moduleBase(m.Name)))
+	}
+	var b strings.Builder
+	for _, m := range modules {
+		line := fmt.Sprintf("  %s %9s  %-*s", addrStyle.Render(fmt.Sprintf("0x%016x", m.Base)), formatSize(int(m.Size)), nameWidth, moduleBase(m.Name))
+		var ids []string
+		if m.DebugID != "" {
+			ids = append(ids, m.DebugID)
+		} else {
+			ids = append(ids, "(no debug id)")
+		}
+		if m.DebugFile != "" && m.DebugFile != m.Name {
+			ids = append(ids, moduleBase(m.DebugFile))
+		}
+		if m.Version != "" {
+			ids = append(ids, m.Version)
+		}
+		b.WriteString(line + "  " + helpStyle.Render(strings.Join(ids, " · ")) + "\n")
+	}
+	return b.String()
+}
+
+func formatAnnotations(md *minidump.Minidump) string {
+	var b strings.Builder
+	writeAnnotations := func(indent string, annotations []minidump.Annotation) {
+		for _, a := range annotations {
+			b.WriteString(indent + helpStyle.Render(a.Key+" =") + " " + a.Value + "\n")
+		}
+	}
+	writeAnnotations("  ", md.Crashpad.Annotations)
+	for _, m := range md.Crashpad.Modules {
+		if len(m.List) == 0 && len(m.Annotations) == 0 {
+			continue
+		}
+		name := fmt.Sprintf("module %d", m.Module)
+		if m.Module < len(md.Modules) {
+			name = moduleBase(md.Modules[m.Module].Name)
+		}
+		b.WriteString("  " + name + "\n")
+		for _, s := range m.List {
+			b.WriteString("    " + s + "\n")
+		}
+		writeAnnotations("    ", m.Annotations)
+	}
+	if b.Len() == 0 {
+		return "  " + helpStyle.Render("no annotations") + "\n"
+	}
+	return b.String()
+}
+
+// crashedThread returns the index of the thread that caught the exception,
+// or -1.
+func crashedThread(md *minidump.Minidump) int {
+	for i, t := range md.Threads {
+		if md.Exception != nil && md.Exception.ThreadID == t.ID {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatMinidumpThread renders the registers and the stack memory of a
+// thread, labelling values that point into loaded modules.
+func formatMinidumpThread(md *minidump.Minidump, t *minidump.Thread) string {
+	var b strings.Builder
+	title := []string{fmt.Sprintf("Thread %d", t.ID)}
+	if md.Exception != nil && md.Exception.ThreadID == t.ID {
+		title = append(title, "crashed")
+	}
+	if t.Name != "" {
+		title = append(title, t.Name)
+	}
+	b.WriteString(labelStyle.Render(strings.Join(title, " · ")) + "\n\n")
+
+	ctx, err := md.ThreadContext(t)
+	b.WriteString(labelStyle.Render("Registers") + "\n")
+	if err != nil {
+		b.WriteString("  " + errorStyle.Render(err.Error()) + "\n")
+	} else {
+		b.WriteString(formatRegisters(md, ctx))
+	}
+
+	b.WriteString("\n" + labelStyle.Render(fmt.Sprintf("Stack 0x%x · %s", t.Stack.Start, formatSize(int(t.Stack.Size)))) + "\n")
+	b.WriteString(formatStackMemory(md, t, ctx))
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// formatRegisters lays out the registers in three columns, followed by the
+// registers that point into modules.
+func formatRegisters(md *minidump.Minidump, ctx *minidump.Context) string {
+	var b strings.Builder
+	for i, r := range ctx.Registers {
+		if i%3 == 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(fmt.Sprintf(" %s %s", helpStyle.Render(fmt.Sprintf("%6s", r.Name)), addrStyle.Render(fmt.Sprintf("0x%016x", r.Value))))
+		if i%3 == 2 || i == len(ctx.Registers)-1 {
+			b.WriteString("\n")
+		}
+	}
+	for _, r := range ctx.Registers {
+		if label := pointerLabel(md, r.Value); label != "" {
+			b.WriteString(fmt.Sprintf("  %s %s %s\n", helpStyle.Render(fmt.Sprintf("%6s", r.Name)), helpStyle.Render("→"), label))
+		}
+	}
+	return b.String()
+}
+
+// minZeroRun is the number of consecutive zero words from which the stack
+// view collapses them into one line.
+const minZeroRun = 4
+
+// formatStackMemory renders the stack memory of a thread one word per line.
+// Words below the stack pointer are dimmed, as they are no longer in use.
+func formatStackMemory(md *minidump.Minidump, t *minidump.Thread, ctx *minidump.Context) string {
+	data, err := md.StackMemory(t)
+	if err != nil {
+		return "  " + errorStyle.Render(err.Error()) + "\n"
+	}
+	size := 8
+	if md.SystemInfo != nil {
+		size = md.SystemInfo.Arch.PointerSize()
+	}
+	var sp uint64
+	if ctx != nil {
+		sp = ctx.SP()
+	}
+
+	var b strings.Builder
+	var zeros []string
+	flush := func() {
+		if len(zeros) >= minZeroRun {
+			b.WriteString(helpStyle.Render(fmt.Sprintf("  ⋮ %d zero words", len(zeros))) + "\n")
+		} else {
+			for _, line := range zeros {
+				b.WriteString(line)
+			}
+		}
+		zeros = zeros[:0]
+	}
+	for off := 0; off+size <= len(data); off += size {
+		addr := t.Stack.Start + uint64(off)
+		word := data[off : off+size]
+		var value uint64
+		if size == 8 {
+			value = binary.LittleEndian.Uint64(word)
+		} else {
+			value = uint64(binary.LittleEndian.Uint32(word))
+		}
+
+		line := fmt.Sprintf("  0x%0*x  % x", 2*size, addr, word)
+		if addr < sp {
+			line = helpStyle.Render(line)
+		}
+		if label := pointerLabel(md, value); label != "" {
+			line += "  " + label
+		}
+		atSP := sp != 0 && sp >= addr && sp < addr+uint64(size)
+		if atSP {
+			line += "  " + warningStyle.Render("← sp")
+		}
+		if value == 0 && !atSP {
+			zeros = append(zeros, line+"\n")
+			continue
+		}
+		flush()
+		b.WriteString(line + "\n")
+	}
+	flush()
+	return b.String()
+}
+
+// pointerLabel returns module+offset for an address within a loaded
+// module, or an empty string.
+func pointerLabel(md *minidump.Minidump, addr uint64) string {
+	m := md.ModuleAt(addr)
+	if m == nil {
+		return ""
+	}
+	return inAppStyle.Render(moduleBase(m.Name)) + fmt.Sprintf("+0x%x", addr-m.Base)
+}
+
+// moduleBase returns the file name of a module path, which may be a
+// Windows path regardless of the platform slope runs on.
+func moduleBase(path string) string {
+	return path[strings.LastIndexAny(path, `/\`)+1:]
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/getsentry/slope/envelope"
+	"github.com/getsentry/slope/envelope/minidump"
+)
+
+// breakpadItem returns the minidump attachment of the breakpad test
+// envelope.
+func breakpadItem(t *testing.T) envelope.Item {
+	t.Helper()
+	f, err := os.Open("../envelope/testdata/breakpad.envelope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	env, err := envelope.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return env.Items[2]
+}
+
+func TestFormatMinidump(t *testing.T) {
+	item := breakpadItem(t)
+	md, err := item.Minidump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ansi.Strip(formatMinidump(md))
+	for _, want := range []string{
+		"EXCEPTION_ACCESS_VIOLATION (0xc0000005) at 0x7ffe2c7f0476 in VCRUNTIME140D.dll",
+		"thread 12996 · parameters 0x1, 0x20",
+		"os          Windows 10.0.22621",
+		"cpu         x86_64 · 12 processors",
+		"written     2024-10-21 20:38:00.000",
+		"Threads (5)",
+		"12996    crashed stack 0x14e958 · 5.7 KB",
+		"31424    sentry-http",
+		"Modules (34)",
+		"sentry_example.exe    ed775832-b988-4ede-a32e-4a105d0e9057-2 · sentry_example.pdb",
+		"ntdll.dll             fb228b94-3d71-8a04-2641-5a200e27cb76-1 · ntdll.pdb · 6.2.22621.4317",
+		"Streams (15)",
+		"ModuleList",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("minidump view should contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Annotations") || strings.Contains(got, "Unused") {
+		t.Errorf("unexpected section, got:\n%s", got)
+	}
+}
+
+func TestFormatMinidumpAnnotations(t *testing.T) {
+	md := &minidump.Minidump{
+		Modules: []minidump.Module{{Name: "/usr/lib/libapp.so"}},
+		Crashpad: &minidump.CrashpadInfo{
+			Annotations: []minidump.Annotation{{Key: "channel", Value: "beta"}},
+			Modules: []minidump.ModuleAnnotations{
+				{Module: 0, List: []string{"abort message"}, Annotations: []minidump.Annotation{{Key: "sentry", Value: "1"}}},
+				{Module: 3},
+			},
+		},
+	}
+	got := ansi.Strip(formatMinidump(md))
+	want := "Annotations\n  channel = beta\n  libapp.so\n    abort message\n    sentry = 1\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestModuleBase(t *testing.T) {
+	for path, want := range map[string]string{
+		`C:\Windows\System32\ntdll.dll`: "ntdll.dll",
+		"/usr/lib/libc.so.6":            "libc.so.6",
+		"app":                           "app",
+	} {
+		if got := moduleBase(path); got != want {
+			t.Errorf("moduleBase(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestFormatMinidumpThread(t *testing.T) {
+	item := breakpadItem(t)
+	md, err := item.Minidump()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := ansi.Strip(formatMinidumpThread(md, md.Thread(31424)))
+	for _, want := range []string{
+		"Thread 31424 · sentry-http",
+		"rax 0x00000000000001e0    rbx 0x0000000000000000    rcx 0x00000000005bf158",
+		"rip → ntdll.dll+0xa3cc4",
+		"Stack 0x1a1fe38 · 456 B",
+		"0x0000000001a1fe38  e4 91 53 53 fe 7f 00 00  ntdll.dll+0x691e4  ← sp",
+		"0x0000000001a1fe40  cc cc cc cc cc cc cc cc\n",
+		"  ⋮ 6 zero words\n  0x0000000001a1feb8  29 f5 e2 50 fe 7f 00 00  KERNELBASE.dll+0x6f529",
+		// Shorter runs are kept
+		"0x0000000001a1fec8  00 00 00 00 00 00 00 00\n  0x0000000001a1fed0  00 00 00 00 00 00 00 00\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("thread view should contain %q, got:\n%s", want, got)
+		}
+	}
+
+	got = ansi.Strip(formatMinidumpThread(md, md.Thread(md.Exception.ThreadID)))
+	for _, want := range []string{
+		"Thread 12996 · crashed",
+		"rip → VCRUNTIME140D.dll+0x20476",
+		"0x000000000014fb08  ",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("crashed thread view should contain %q, got:\n%s", want, got)
+		}
+	}
+	if !strings.Contains(got, "← sp") {
+		t.Error("crashed thread view should mark the stack pointer")
+	}
+}
+
+func TestCrashedThread(t *testing.T) {
+	item := breakpadItem(t)
+	md, err := item.Minidump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := crashedThread(md); got != 0 {
+		t.Errorf("crashedThread = %d, want 0", got)
+	}
+	md.Exception = nil
+	if got := crashedThread(md); got != -1 {
+		t.Errorf("crashedThread without exception = %d, want -1", got)
+	}
+}
@@ -16,7 +16,7 @@ import (
 	tea "charm.land/bubbletea/v2"
 	lipgloss "charm.land/lipgloss/v2"
 	"github.com/getsentry/slope/envelope"
-	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"github.com/getsentry/slope/symbolicate"
 )
 
 type editResultMsg struct {
@@ -32,19 +32,29 @@ const (
 	modeInput
 	modeExport
 	modeConfirmQuit
+	modeDetail
 )
 
 type Model struct {
-	envelope *envelope.Envelope
-	filePath string
-	fileSize int64
-	selected int
-	mode     viewMode
-	picker   filepicker.Model
-	export   textinput.Model
-	dirty    bool
-	message  string
-	width    int
+	envelope    *envelope.Envelope
+	diagnostics []*envelope.ParseError
+	findings    []envelope.Finding
+	filePath    string
+	fileSize    int64
+	encoding    envelope.Encoding // of the file as loaded or last saved
+	rawSize     int               // of the envelope uncompressed, until changed
+	selected    int
+	mode        viewMode
+	picker      filepicker.Model
+	export      textinput.Model
+	dirty       bool
+	message     string
+	width       int
+	height      int
+	detail      detailView
+	summaries   map[int]string // rendered summaries by item, until changed
+
+	symbolicator *symbolicate.Symbolicator
 }
 
 func NewModel(env *envelope.Envelope, filePath string, fileSize int64) Model {
@@ -58,12 +68,24 @@ func NewModel(env *envelope.Envelope, filePath string, fileSize int64) Model {
 	fp.SetHeight(20)
 	fp.Styles.Cursor = lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
 	fp.Styles.Selected = lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Bold(true)
-	return Model{
-		envelope: env,
-		filePath: filePath,
-		fileSize: fileSize,
-		picker:   fp,
+	m := Model{
+		envelope:  env,
+		findings:  envelope.Validate(env),
+		filePath:  filePath,
+		fileSize:  fileSize,
+		encoding:  env.Encoding,
+		rawSize:   uncompressedSize(env),
+		picker:    fp,
+		summaries: map[int]string{},
 	}
+	m.summarizeSelected()
+	return m
+}
+
+// SetDiagnostics sets the problems found while leniently parsing the
+// envelope, which are shown along with the envelope header.
+func (m *Model) SetDiagnostics(diags []*envelope.ParseError) {
+	m.diagnostics = diags
 }
 
 func (m Model) itemCount() int {
@@ -78,20 +100,81 @@ func (m Model) buildDump() string {
 	var b strings.Builder
 	sep := m.separator()
 
-	b.WriteString(labelStyle.Render(
-		fmt.Sprintf("%s · %s", filepath.Base(m.filePath), formatSize(int(m.fileSize))),
-	) + "\n")
+	title := fmt.Sprintf("%s · %s", filepath.Base(m.filePath), formatSize(int(m.fileSize)))
+	if m.encoding != envelope.EncodingNone {
+		title += fmt.Sprintf(" %s · %s uncompressed", m.encoding, formatSize(m.rawSize))
+	}
+	if m.envelope.Encoding != m.encoding {
+		title += " · saving as " + m.envelope.Encoding.String()
+	}
+	b.WriteString(labelStyle.Render(title) + "\n")
 	b.WriteString(sep + "\n")
 	b.WriteString(formatHeader(m.envelope.Header, m.width) + "\n")
+	for _, d := range m.diagnostics {
+		b.WriteString(errorStyle.Render("! "+d.Error()) + "\n")
+	}
+	b.WriteString(m.formatFindings(-1))
 
 	for i, item := range m.envelope.Items {
 		b.WriteString("\n" + labelStyle.Render(itemLabel(i, item)) + "\n")
 		b.WriteString(sep + "\n")
 		b.WriteString(formatHeader(item.Header, m.width) + "\n")
+		b.WriteString(m.formatFindings(i))
 	}
 	return b.String()
 }
 
+func uncompressedSize(env *envelope.Envelope) int {
+	raw := *env
+	raw.Encoding = envelope.EncodingNone
+	var n byteCounter
+	raw.SerializeLossless(&n)
+	return int(n)
+}
+
+// itemsChanged revalidates the envelope and drops the cached sizes and
+// summaries after items have been edited, added or deleted.
+func (m *Model) itemsChanged() {
+	m.findings = envelope.Validate(m.envelope)
+	m.rawSize = uncompressedSize(m.envelope)
+	clear(m.summaries)
+	m.summarizeSelected()
+	m.dirty = true
+}
+
+// modified reports whether the envelope has changes to save: edited items,
+// or another encoding than the file has.
+func (m Model) modified() bool {
+	return m.dirty || m.envelope.Encoding != m.encoding
+}
+
+func (m Model) formatFindings(item int) string {
+	var b strings.Builder
+	for _, f := range m.findings {
+		if f.Item != item {
+			continue
+		}
+		line := fmt.Sprintf("%s: %s [%s]", f.Severity, f.Message, f.Rule)
+		b.WriteString(severityStyle(f.Severity).Render("! "+line) + "\n")
+	}
+	return b.String()
+}
+
+// findingsMarker returns a marker for the most severe finding of an item.
+func (m Model) findingsMarker(item int) string {
+	marker := ""
+	for _, f := range m.findings {
+		if f.Item != item {
+			continue
+		}
+		if f.Severity == envelope.SeverityError {
+			return errorStyle.Render(" ✗")
+		}
+		marker = warningStyle.Render(" !")
+	}
+	return marker
+}
+
 func (m Model) printDump() tea.Cmd {
 	return tea.Println(m.buildDump())
 }
@@ -100,7 +183,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
+		m.height = msg.Height
 		m.picker.SetHeight(max(msg.Height-5, 1))
+		if m.mode == modeDetail {
+			m.refreshDetail()
+		}
 	case editResultMsg:
 		if msg.err != nil {
 			m.message = errorStyle.Render("Error: " + msg.err.Error())
@@ -111,13 +198,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		item.Payload = msg.payload
-		header, err := envelope.UpdateLength(item.Header, len(msg.payload))
-		if err != nil {
+		if err := item.Set("length", len(msg.payload)); err != nil {
 			m.message = errorStyle.Render("Error: " + err.Error())
 			return m, nil
 		}
-		item.Header = header
-		m.dirty = true
+		m.itemsChanged()
 		m.message = savedStyle.Render("Payload updated")
 		return m, m.printDump()
 	case tea.KeyPressMsg:
@@ -132,6 +217,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case modeExport:
 			return m.updateExport(msg)
+		case modeDetail:
+			return m.updateDetail(msg)
 		case modeConfirmQuit:
 			switch msg.String() {
 			case keyY:
@@ -154,13 +241,18 @@ func (m Model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 	case keyUp, keyK:
 		if m.selected > 0 {
 			m.selected--
+			m.summarizeSelected()
 		}
 	case keyDown, keyJ:
 		if m.selected < m.itemCount()-1 {
 			m.selected++
+			m.summarizeSelected()
 		}
 	case keyEnter:
 		if m.itemCount() > 0 {
+			if d, ok := m.selectedDetail(); ok {
+				return m.openDetail(d)
+			}
 			return m, m.viewInPager()
 		}
 	case keyE:
@@ -173,7 +265,7 @@ func (m Model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			if m.selected >= m.itemCount() && m.itemCount() > 0 {
 				m.selected = m.itemCount() - 1
 			}
-			m.dirty = true
+			m.itemsChanged()
 			m.message = "Item deleted"
 			return m, m.printDump()
 		}
@@ -184,11 +276,19 @@ func (m Model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.mode = modeExport
 			return m, m.export.Focus()
 		}
+	case keyC:
+		m.envelope.Encoding = nextEncoding(m.envelope.Encoding)
+		m.message = "Compression: " + m.envelope.Encoding.String()
+		return m, m.printDump()
+	case keyS:
+		if m.canSymbolicate() {
+			return m.symbolicateSelected()
+		}
 	case keyA:
 		m.mode = modeInput
 		return m, m.picker.Init()
 	case keyW:
-		if !m.dirty {
+		if !m.modified() {
 			return m, nil
 		}
 		size, err := m.writeFile()
@@ -197,11 +297,12 @@ func (m Model) updateList(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		} else {
 			m.dirty = false
 			m.fileSize = size
+			m.encoding = m.envelope.Encoding
 			m.message = savedStyle.Render("Saved " + m.filePath)
 			return m, m.printDump()
 		}
 	case keyQ, keyCtrlC:
-		if m.dirty {
+		if m.modified() {
 			m.mode = modeConfirmQuit
 			return m, nil
 		}
@@ -307,7 +408,7 @@ func (m Model) updatePicker(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.mode = modeList
 			return m, nil
 		}
-		m.dirty = true
+		m.itemsChanged()
 		m.message = savedStyle.Render("Added " + filepath.Base(path))
 		m.mode = modeList
 		return m, m.printDump()
@@ -364,12 +465,16 @@ func (m Model) View() tea.View {
 		if m.itemCount() > 0 {
 			for i, item := range m.envelope.Items {
 				label := itemLabel(i, item)
+				marker := m.findingsMarker(i)
 				if i == m.selected {
-					b.WriteString("> " + selectedLabelStyle.Render(label) + "\n")
+					b.WriteString("> " + selectedLabelStyle.Render(label) + marker + "\n")
 				} else {
-					b.WriteString("  " + label + "\n")
+					b.WriteString("  " + label + marker + "\n")
 				}
 			}
+			if summary := m.selectedSummary(); summary != "" {
+				b.WriteString("\n" + summary)
+			}
 		}
 	case modeInput:
 		b.WriteString(labelStyle.Render("Select file to attach") + "\n\n")
@@ -378,6 +483,9 @@ func (m Model) View() tea.View {
 		b.WriteString(labelStyle.Render("Export to: ") + m.export.View() + "\n")
 	case modeConfirmQuit:
 		b.WriteString(errorStyle.Render("Unsaved changes. Quit anyway?") + "\n")
+	case modeDetail:
+		b.WriteString(labelStyle.Render(m.detailTitle()) + "\n")
+		b.WriteString(m.detail.viewport.View() + "\n")
 	}
 
 	if m.message != "" {
@@ -404,22 +512,29 @@ func (m Model) helpText() string {
 		return helpStyle.Render("enter confirm · esc cancel")
 	case modeConfirmQuit:
 		return helpStyle.Render("y quit · any key cancel")
+	case modeDetail:
+		return helpStyle.Render(m.detailHelp())
 	default:
 		dirty := ""
-		if m.dirty {
+		if m.modified() {
 			dirty = " · (modified)"
 		}
 		editStyle := helpStyle
 		if m.itemCount() == 0 || envelope.IsBinary(m.envelope.Items[m.selected].Payload) {
 			editStyle = helpDisabledStyle
 		}
+		symbolicateStyle := helpStyle
+		if !m.canSymbolicate() {
+			symbolicateStyle = helpDisabledStyle
+		}
 		saveStyle := helpStyle
-		if !m.dirty {
+		if !m.modified() {
 			saveStyle = helpDisabledStyle
 		}
 		return helpStyle.Render("↑/↓ navigate · enter view · a add") +
 			editStyle.Render(" · e edit") +
-			helpStyle.Render(" · x export · d delete") +
+			helpStyle.Render(" · x export · d delete · c compress") +
+			symbolicateStyle.Render(" · s symbolicate") +
 			saveStyle.Render(" · w save") +
 			helpStyle.Render(" · q quit"+dirty)
 	}
@@ -433,7 +548,7 @@ func (m *Model) writeFile() (int64, error) {
 	}
 	tmpPath := tmp.Name()
 
-	if err := m.envelope.Serialize(tmp); err != nil {
+	if err := m.envelope.SerializeLossless(tmp); err != nil {
 		tmp.Close()
 		os.Remove(tmpPath)
 		return 0, err
@@ -457,25 +572,23 @@ func (m *Model) addAttachment(path string) error {
 	if err != nil {
 		return fmt.Errorf("reading %s: %w", path, err)
 	}
-	basename := filepath.Base(path)
-	om := orderedmap.New[string, any]()
-	om.Set("type", "attachment")
-	om.Set("length", len(data))
-	om.Set("filename", basename)
-	if ct := mime.TypeByExtension(filepath.Ext(path)); ct != "" {
-		om.Set("content_type", ct)
-	}
+	ct := mime.TypeByExtension(filepath.Ext(path))
+	m.envelope.Add(envelope.NewAttachmentItem(filepath.Base(path), ct, "", data))
+	return nil
+}
 
-	header, err := json.Marshal(om)
-	if err != nil {
-		return fmt.Errorf("marshaling header: %w", err)
+func nextEncoding(enc envelope.Encoding) envelope.Encoding {
+	for i, e := range envelope.Encodings {
+		if e == enc {
+			return envelope.Encodings[(i+1)%len(envelope.Encodings)]
+		}
 	}
+	return envelope.EncodingNone
+}
 
-	m.envelope.Items = append(m.envelope.Items, envelope.Item{
-		Header:   json.RawMessage(header),
-		Payload:  data,
-		Type:     "attachment",
-		Filename: basename,
-	})
-	return nil
+type byteCounter int
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
 }
@@ -11,6 +11,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/getsentry/slope/envelope"
 )
 
@@ -217,6 +218,42 @@ func TestModelSave(t *testing.T) {
 	}
 }
 
+func TestModelSaveMinimalDiff(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.envelope")
+	original := `{ "event_id": "abc123" }` + "\n" +
+		`{ "type": "attachment" }` + "\n" +
+		"hello\n" +
+		`{"type":"event","length":2}` + "\n" +
+		"{}\n"
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env, err := envelope.Parse(strings.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel(env, path, int64(len(original)))
+	m = update(m, key('j'), editResultMsg{index: 1, payload: []byte(`{"a":1}`)}, key('w'))
+	if m.dirty {
+		t.Fatal("dirty should be false after save")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{ "event_id": "abc123" }` + "\n" +
+		`{ "type": "attachment" }` + "\n" +
+		"hello\n" +
+		`{"type":"event","length":7}` + "\n" +
+		`{"a":1}` + "\n"
+	if string(data) != want {
+		t.Errorf("saved:\n%q\nwant:\n%q", data, want)
+	}
+}
+
 func TestModelSavePreservesFileOnError(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "test.envelope")
@@ -537,6 +574,108 @@ func TestBuildDump(t *testing.T) {
 	}
 }
 
+func TestBuildDumpDiagnostics(t *testing.T) {
+	input := "{}\n" +
+		"not json\n" +
+		`{"type":"session"}` + "\n" +
+		`{"sid":"x"}` + "\n"
+	env, diags, err := envelope.ParseLenient(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel(env, "test.envelope", int64(len(input)))
+	m.SetDiagnostics(diags)
+	m.width = 80
+	dump := m.buildDump()
+
+	if !strings.Contains(dump, "invalid JSON in item header") {
+		t.Error("dump should contain diagnostic")
+	}
+	if !strings.Contains(dump, "BROKEN") {
+		t.Error("dump should flag broken item")
+	}
+	if !strings.Contains(dump, "SESSION") {
+		t.Error("dump should contain recovered item")
+	}
+}
+
+func TestCompression(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.envelope")
+	m := testModel(1)
+	m.filePath = path
+
+	m = update(m, key('c'))
+	if m.envelope.Encoding != envelope.EncodingGzip {
+		t.Fatalf("encoding = %s, want gzip", m.envelope.Encoding)
+	}
+	if !m.modified() {
+		t.Error("modified = false, want true")
+	}
+	if dump := m.buildDump(); !strings.Contains(dump, "test.envelope · 0 B · saving as gzip") {
+		t.Errorf("dump should show the size on disk and the new compression, got %q", dump)
+	}
+
+	m = update(m, key('w'))
+	if m.modified() {
+		t.Error("modified = true after save")
+	}
+	if dump := m.buildDump(); !strings.Contains(dump, "gzip · 55 B uncompressed") {
+		t.Errorf("dump should show compression and uncompressed size, got %q", dump)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	env, err := envelope.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Encoding != envelope.EncodingGzip || len(env.Items) != 1 {
+		t.Errorf("saved encoding = %s, items = %d", env.Encoding, len(env.Items))
+	}
+
+	for range envelope.Encodings[2:] {
+		m = update(m, key('c'))
+	}
+	m = update(m, key('c'))
+	if m.envelope.Encoding != envelope.EncodingNone {
+		t.Errorf("encoding = %s, want none after full cycle", m.envelope.Encoding)
+	}
+	m = update(m, key('c'))
+	if m.modified() {
+		t.Error("modified = true after cycling back to the saved compression")
+	}
+}
+
+func TestFindings(t *testing.T) {
+	m := testModel(2)
+	m.envelope.Items = append(m.envelope.Items, envelope.Item{
+		Header:  json.RawMessage(`{"type":"attachment","length":1,"attachment_type":"foo"}`),
+		Payload: []byte("x"),
+		Type:    "attachment",
+	})
+	m = update(m, key('j'), key('d'))
+	if len(m.findings) != 2 {
+		t.Fatalf("findings = %v, want 2", m.findings)
+	}
+
+	dump := m.buildDump()
+	if !strings.Contains(dump, "[missing-filename]") || !strings.Contains(dump, "[unknown-attachment-type]") {
+		t.Errorf("dump should contain findings, got %q", dump)
+	}
+	if m.findingsMarker(0) != "" {
+		t.Error("item 0 should have no marker")
+	}
+	if !strings.Contains(m.findingsMarker(1), "✗") {
+		t.Error("item 1 should have error marker")
+	}
+	if !strings.Contains(viewText(m), "✗") {
+		t.Error("view should contain error marker")
+	}
+}
+
 func TestView(t *testing.T) {
 	t.Run("list", func(t *testing.T) {
 		m := testModel(1)
@@ -638,3 +777,179 @@ func TestEditResultUpdateLengthError(t *testing.T) {
 		t.Errorf("expected error message, got %q", m.message)
 	}
 }
+
+func TestDetailStacktrace(t *testing.T) {
+	m := testModel(0)
+	m.envelope.Add(envelope.Item{
+		Header:  json.RawMessage(`{"type":"event"}`),
+		Payload: []byte(nativeCrash),
+		Type:    "event",
+	})
+	m = update(m, tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	m = update(m, specialKey(tea.KeyEnter))
+	if m.mode != modeDetail {
+		t.Fatalf("enter on crash: mode = %d, want modeDetail", m.mode)
+	}
+	v := ansi.Strip(viewText(m))
+	for _, want := range []string{"1. EVENT", "Stacktrace", "SIGSEGV: Segfault", "main at main.c:3", "r raw json"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("stacktrace view should contain %q, got:\n%s", want, v)
+		}
+	}
+
+	m = update(m, key('r'))
+	v = ansi.Strip(viewText(m))
+	if !strings.Contains(v, "JSON") || !strings.Contains(v, `"exception"`) {
+		t.Errorf("raw view should contain the JSON payload, got:\n%s", v)
+	}
+
+	m = update(m, key('r'))
+	if !strings.Contains(viewText(m), "Stacktrace") {
+		t.Error("r should toggle back to the stacktrace")
+	}
+
+	m = update(m, key('q'))
+	if m.mode != modeList {
+		t.Errorf("q from detail: mode = %d, want modeList", m.mode)
+	}
+}
+
+func TestDetailBreadcrumbs(t *testing.T) {
+	m := testModel(0)
+	m.envelope.Add(envelope.Item{
+		Header:  json.RawMessage(`{"type":"event"}`),
+		Payload: []byte(breadcrumbEvent),
+		Type:    "event",
+	})
+
+	m = update(m, specialKey(tea.KeyEnter))
+	if m.mode != modeDetail || m.detail.kind != detailBreadcrumbs {
+		t.Fatalf("enter on event with breadcrumbs only: mode = %d, kind = %v", m.mode, m.detail.kind)
+	}
+	v := ansi.Strip(viewText(m))
+	if !strings.Contains(v, "Breadcrumbs · 3/3") || !strings.Contains(v, ">  -3.5s") {
+		t.Errorf("breadcrumbs view, got:\n%s", v)
+	}
+	if strings.Contains(v, "tab next view") {
+		t.Error("help should not offer other views")
+	}
+
+	m = update(m, key('j'), specialKey(tea.KeyEnter))
+	if v := ansi.Strip(viewText(m)); !strings.Contains(v, `"url": "/api"`) {
+		t.Errorf("enter should expand data, got:\n%s", v)
+	}
+
+	m = update(m, key('v'), key('v'))
+	if v := ansi.Strip(viewText(m)); !strings.Contains(v, "level ≥ warning · 1/3") || m.detail.cursor != 0 {
+		t.Errorf("level filter, got:\n%s", v)
+	}
+	m = update(m, key('v'), key('v'), key('v'), key('c'))
+	if v := ansi.Strip(viewText(m)); !strings.Contains(v, "category http · 1/3") {
+		t.Errorf("category filter, got:\n%s", v)
+	}
+}
+
+func TestDetailTab(t *testing.T) {
+	ev := nativeCrash[:len(nativeCrash)-1] + `,"breadcrumbs":[{"message":"hello"}]}`
+	m := testModel(0)
+	m.envelope.Add(envelope.Item{
+		Header:  json.RawMessage(`{"type":"event"}`),
+		Payload: []byte(ev),
+		Type:    "event",
+	})
+
+	m = update(m, specialKey(tea.KeyEnter))
+	if m.detail.kind != detailStacktrace {
+		t.Fatalf("kind = %v, want stacktrace", m.detail.kind)
+	}
+	m = update(m, specialKey(tea.KeyTab))
+	if m.detail.kind != detailBreadcrumbs || !strings.Contains(ansi.Strip(viewText(m)), "hello") {
+		t.Errorf("tab: kind = %v, want breadcrumbs", m.detail.kind)
+	}
+	m = update(m, key('r'), specialKey(tea.KeyTab))
+	if m.detail.kind != detailStacktrace || m.detail.raw {
+		t.Errorf("tab from raw: kind = %v, raw = %v", m.detail.kind, m.detail.raw)
+	}
+}
+
+func TestDetailSpans(t *testing.T) {
+	m := testModel(0)
+	m.envelope.Add(
+		envelope.Item{
+			Header:  json.RawMessage(`{"type":"transaction"}`),
+			Payload: []byte(transactionEvent),
+			Type:    "transaction",
+		},
+		envelope.Item{
+			Header:  json.RawMessage(`{"type":"span"}`),
+			Payload: []byte(`{"span_id":"s1","op":"queue.process","description":"job","start_timestamp":1,"timestamp":2}`),
+			Type:    "span",
+		},
+	)
+	m = update(m, tea.WindowSizeMsg{Width: 100, Height: 30})
+
+	m = update(m, specialKey(tea.KeyEnter))
+	if m.mode != modeDetail || m.detail.kind != detailSpans {
+		t.Fatalf("enter on transaction: mode = %d, kind = %v", m.mode, m.detail.kind)
+	}
+	v := ansi.Strip(viewText(m))
+	for _, want := range []string{"TRANSACTION", "Spans", "http.server GET /api", "db SELECT 1", "internal_error"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("waterfall should contain %q, got:\n%s", want, v)
+		}
+	}
+
+	m = update(m, key('q'), key('j'), specialKey(tea.KeyEnter))
+	if v := ansi.Strip(viewText(m)); m.detail.kind != detailSpans || !strings.Contains(v, "queue.process job") {
+		t.Errorf("span item view, got:\n%s", v)
+	}
+}
+
+func TestDetailMinidump(t *testing.T) {
+	m := testModel(0)
+	m.envelope.Add(breakpadItem(t))
+	m = update(m, tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	m = update(m, specialKey(tea.KeyEnter))
+	if m.mode != modeDetail || m.detail.kind != detailMinidump {
+		t.Fatalf("enter on minidump: mode = %d, kind = %v", m.mode, m.detail.kind)
+	}
+	v := ansi.Strip(viewText(m))
+	for _, want := range []string{"Minidump", "EXCEPTION_ACCESS_VIOLATION", "r raw hex"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("minidump view should contain %q, got:\n%s", want, v)
+		}
+	}
+
+	m = update(m, key('r'))
+	if v := ansi.Strip(viewText(m)); !strings.Contains(v, "Hex") || !strings.Contains(v, "4d 44 4d 50") {
+		t.Errorf("raw view should be a hex dump, got:\n%s", v)
+	}
+}
+
+func TestDetailMinidumpThreads(t *testing.T) {
+	m := testModel(0)
+	m.envelope.Add(breakpadItem(t))
+	m = update(m, tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	m = update(m, specialKey(tea.KeyEnter), specialKey(tea.KeyTab))
+	if m.detail.kind != detailThreads || m.detail.thread != 0 {
+		t.Fatalf("tab: kind = %v, thread = %d", m.detail.kind, m.detail.thread)
+	}
+	v := ansi.Strip(viewText(m))
+	for _, want := range []string{"Threads · 12996 · 1/5", "Thread 12996 · crashed", "n/p thread"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("threads view should contain %q, got:\n%s", want, v)
+		}
+	}
+
+	m = update(m, key('n'), key('n'), key('n'))
+	if v := ansi.Strip(viewText(m)); !strings.Contains(v, "Thread 31424 · sentry-http") {
+		t.Errorf("n: got:\n%s", v)
+	}
+	m = update(m, key('p'), key('p'), key('p'), key('p'))
+	if m.detail.thread != 4 {
+		t.Errorf("p wraps around: thread = %d, want 4", m.detail.thread)
+	}
+}
@@ -0,0 +1,259 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// hasStacktrace reports whether an event has exceptions or threads to show
+// in the stacktrace view.
+func hasStacktrace(ev *event.Event) bool {
+	return len(ev.Exception) > 0 || len(ev.Threads) > 0
+}
+
+// formatStacktrace renders the exceptions of an event, most recent first,
+// followed by its threads, crashed thread first. Frames are listed innermost
+// first.
+func formatStacktrace(ev *event.Event) string {
+	var b strings.Builder
+	shown := map[event.ID]bool{}
+
+	for _, exc := range slices.Backward(ev.Exception) {
+		b.WriteString(labelStyle.Render(exceptionTitle(exc)) + "\n")
+		if m := exc.Mechanism; m != nil {
+			b.WriteString("  " + helpStyle.Render("mechanism:") + " " + formatMechanism(m) + "\n")
+		}
+		st := exc.Stacktrace
+		if st == nil && exc.ThreadID != "" {
+			// Native SDKs attach the stacktrace to the crashed thread instead
+			if t := findThread(ev.Threads, exc.ThreadID); t != nil && t.Stacktrace != nil {
+				st = t.Stacktrace
+				shown[t.ID] = true
+			}
+		}
+		b.WriteString(formatFrames(st))
+		b.WriteString("\n")
+	}
+
+	threads := slices.Clone(ev.Threads)
+	slices.SortStableFunc(threads, func(a, b event.Thread) int {
+		switch {
+		case a.Crashed && !b.Crashed:
+			return -1
+		case b.Crashed && !a.Crashed:
+			return 1
+		}
+		return 0
+	})
+	for _, t := range threads {
+		if shown[t.ID] && t.ID != "" {
+			continue
+		}
+		b.WriteString(labelStyle.Render(threadTitle(t)) + "\n")
+		b.WriteString(formatFrames(t.Stacktrace))
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func exceptionTitle(exc event.Exception) string {
+	title := exc.Type
+	if exc.Module != "" {
+		title = exc.Module + "." + title
+	}
+	switch {
+	case title == "":
+		title = exc.Value
+	case exc.Value != "":
+		title += ": " + exc.Value
+	}
+	if title == "" {
+		title = "(unknown exception)"
+	}
+	return title
+}
+
+func formatMechanism(m *event.Mechanism) string {
+	parts := []string{m.Type}
+	if m.Handled != nil {
+		if *m.Handled {
+			parts = append(parts, "handled")
+		} else {
+			parts = append(parts, errorStyle.Render("unhandled"))
+		}
+	}
+	if m.Synthetic {
+		parts = append(parts, "synthetic")
+	}
+	if signal, ok := m.Meta["signal"].(map[string]any); ok {
+		parts = append(parts, "signal "+metaName(signal))
+	}
+	if mach, ok := m.Meta["mach_exception"].(map[string]any); ok {
+		parts = append(parts, "mach exception "+metaName(mach))
+	}
+	if errno, ok := m.Meta["errno"].(map[string]any); ok {
+		parts = append(parts, "errno "+metaName(errno))
+	}
+	if m.Description != "" {
+		parts = append(parts, m.Description)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// metaName formats a named code from the mechanism meta, such as a signal.
+func metaName(meta map[string]any) string {
+	name, _ := meta["name"].(string)
+	code, ok := meta["number"]
+	if !ok {
+		code, ok = meta["exception"]
+	}
+	switch {
+	case !ok:
+		return name
+	case name == "":
+		return fmt.Sprint(code)
+	default:
+		return fmt.Sprintf("%s (%v)", name, code)
+	}
+}
+
+func threadTitle(t event.Thread) string {
+	title := "Thread"
+	if t.ID != "" {
+		title += " " + string(t.ID)
+	}
+	if t.Name != "" {
+		title += " " + t.Name
+	}
+	var flags []string
+	if t.Crashed {
+		flags = append(flags, "crashed")
+	}
+	if t.Current {
+		flags = append(flags, "current")
+	}
+	if t.Main {
+		flags = append(flags, "main")
+	}
+	if len(flags) > 0 {
+		title += " (" + strings.Join(flags, ", ") + ")"
+	}
+	return title
+}
+
+func findThread(threads []event.Thread, id event.ID) *event.Thread {
+	for i := range threads {
+		if threads[i].ID == id {
+			return &threads[i]
+		}
+	}
+	return nil
+}
+
+func formatFrames(st *event.Stacktrace) string {
+	if st == nil || len(st.Frames) == 0 {
+		return helpStyle.Render("  (no stacktrace)") + "\n"
+	}
+	var b strings.Builder
+	width := len(fmt.Sprint(len(st.Frames) - 1))
+	addrWidth := 0
+	for _, frame := range st.Frames {
+		addrWidth = max(addrWidth, len(frame.InstructionAddr))
+	}
+	indent := strings.Repeat(" ", width+4)
+	for i, frame := range slices.Backward(st.Frames) {
+		n := len(st.Frames) - 1 - i
+		b.WriteString(fmt.Sprintf("  %*d  %s\n", width, n, formatFrame(frame, addrWidth)))
+		if frame.InApp != nil && *frame.InApp {
+			b.WriteString(formatContext(frame, indent))
+		}
+	}
+	return b.String()
+}
+
+// formatContext renders the source lines around a frame, numbered if the
+// frame has a line number, with the frame's line marked.
+func formatContext(f event.Frame, indent string) string {
+	if f.ContextLine == "" && len(f.PreContext) == 0 && len(f.PostContext) == 0 {
+		return ""
+	}
+	lines := slices.Concat(f.PreContext, []string{f.ContextLine}, f.PostContext)
+	first := f.Lineno - len(f.PreContext)
+	numWidth := len(fmt.Sprint(first + len(lines) - 1))
+	var b strings.Builder
+	for i, line := range lines {
+		num := ""
+		if f.Lineno > 0 {
+			num = fmt.Sprintf("%*d ", numWidth, first+i)
+		}
+		line = strings.ReplaceAll(line, "\t", "    ")
+		if i == len(f.PreContext) {
+			b.WriteString(indent + inAppStyle.Render("→ "+num+line) + "\n")
+		} else {
+			b.WriteString(indent + helpStyle.Render("  "+num+line) + "\n")
+		}
+	}
+	return b.String()
+}
+
+func formatFrame(f event.Frame, addrWidth int) string {
+	function := f.Function
+	if function == "" {
+		function = f.RawFunction
+	}
+	if function == "" {
+		function = f.Symbol
+	}
+	if function == "" {
+		function = "<unknown>"
+	}
+
+	style := frameStyle
+	if f.InApp != nil && *f.InApp {
+		style = inAppStyle
+	}
+	parts := []string{style.Render(function)}
+	if addrWidth > 0 {
+		addr := fmt.Sprintf("%-*s", addrWidth, f.InstructionAddr)
+		parts = slices.Insert(parts, 0, addrStyle.Render(addr))
+	}
+	if loc := frameLocation(f); loc != "" {
+		parts = append(parts, "at "+loc)
+	}
+	if pkg := framePackage(f); pkg != "" {
+		parts = append(parts, helpStyle.Render("in "+pkg))
+	}
+	return strings.Join(parts, " ")
+}
+
+func frameLocation(f event.Frame) string {
+	file := f.Filename
+	if file == "" {
+		file = f.AbsPath
+	}
+	if file == "" {
+		return ""
+	}
+	switch {
+	case f.Lineno > 0 && f.Colno > 0:
+		return fmt.Sprintf("%s:%d:%d", file, f.Lineno, f.Colno)
+	case f.Lineno > 0:
+		return fmt.Sprintf("%s:%d", file, f.Lineno)
+	}
+	return file
+}
+
+func framePackage(f event.Frame) string {
+	switch {
+	case f.Package != "":
+		// Native packages are paths to binaries
+		return filepath.Base(f.Package)
+	case f.Module != "":
+		return f.Module
+	}
+	return ""
+}
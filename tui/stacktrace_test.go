@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/getsentry/slope/envelope/event"
+)
+
+const nativeCrash = `{"event_id":"4bf326c30e574542e355035a23d438df","level":"fatal","platform":"native",` +
+	`"exception":{"values":[{"type":"SIGSEGV","value":"Segfault","thread_id":1,` +
+	`"mechanism":{"type":"signalhandler","handled":false,"synthetic":true,"meta":{"signal":{"name":"SIGSEGV","number":11}}}}]},` +
+	`"threads":{"values":[{"id":2,"name":"worker"},{"id":1,"name":"main","crashed":true,"stacktrace":{"frames":[` +
+	`{"function":"main","instruction_addr":"0x55d0c0de1000","in_app":true,"filename":"main.c","lineno":3,"package":"/usr/bin/app"},` +
+	`{"function":"crash","instruction_addr":"0x7f0000001010","package":"/lib/libc.so.6"}]}}]}}`
+
+func parseEvent(t *testing.T, payload string) *event.Event {
+	t.Helper()
+	ev, err := event.Parse([]byte(payload))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	return ev
+}
+
+func TestFormatStacktrace(t *testing.T) {
+	got := ansi.Strip(formatStacktrace(parseEvent(t, nativeCrash)))
+	want := "SIGSEGV: Segfault\n" +
+		"  mechanism: signalhandler · unhandled · synthetic · signal SIGSEGV (11)\n" +
+		"  0  0x7f0000001010 crash in libc.so.6\n" +
+		"  1  0x55d0c0de1000 main at main.c:3 in app\n" +
+		"\n" +
+		"Thread 2 worker\n" +
+		"  (no stacktrace)\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatStacktraceThreads(t *testing.T) {
+	ev := parseEvent(t, `{"threads":{"values":[`+
+		`{"id":1,"name":"idle","stacktrace":{"frames":[{"function":"wait"}]}},`+
+		`{"id":2,"name":"worker","crashed":true,"current":true,"stacktrace":{"frames":[{"function":"run","module":"app.worker"},{"function":"fail"}]}}]}}`)
+	got := ansi.Strip(formatStacktrace(ev))
+	want := "Thread 2 worker (crashed, current)\n" +
+		"  0  fail\n" +
+		"  1  run in app.worker\n" +
+		"\n" +
+		"Thread 1 idle\n" +
+		"  0  wait\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatStacktraceChain(t *testing.T) {
+	ev := parseEvent(t, `{"exception":{"values":[`+
+		`{"type":"IOError","value":"disk full","module":"io"},`+
+		`{"type":"RuntimeError","value":"save failed","stacktrace":{"frames":[{"function":"save","filename":"app.py","lineno":10,"colno":5}]}}]}}`)
+	got := ansi.Strip(formatStacktrace(ev))
+	if i, j := strings.Index(got, "RuntimeError: save failed"), strings.Index(got, "io.IOError: disk full"); i < 0 || j < i {
+		t.Errorf("most recent exception should come first, got:\n%s", got)
+	}
+	if !strings.Contains(got, "save at app.py:10:5") {
+		t.Errorf("missing frame location, got:\n%s", got)
+	}
+}
+
+func TestFormatFrameInApp(t *testing.T) {
+	inApp := true
+	if got := formatFrame(event.Frame{Function: "f", InApp: &inApp}, 0); !strings.Contains(got, inAppStyle.Render("f")) {
+		t.Errorf("in-app frame should be highlighted, got %q", got)
+	}
+	if got := formatFrame(event.Frame{Function: "f"}, 0); !strings.Contains(got, frameStyle.Render("f")) {
+		t.Errorf("system frame should be dimmed, got %q", got)
+	}
+	if got := ansi.Strip(formatFrame(event.Frame{}, 0)); got != "<unknown>" {
+		t.Errorf("got %q, want <unknown>", got)
+	}
+}
+
+func TestFormatStacktraceContext(t *testing.T) {
+	ev := parseEvent(t, `{"exception":{"values":[{"type":"Error","stacktrace":{"frames":[`+
+		`{"function":"lib","filename":"lib.js","lineno":1,"context_line":"hidden();"},`+
+		`{"function":"crash","filename":"app.js","lineno":9,"in_app":true,`+
+		`"pre_context":["function crash() {"],"context_line":"\tthrow new Error();","post_context":["}",""]}]}}]}}`)
+	got := ansi.Strip(formatStacktrace(ev))
+	want := "Error\n" +
+		"  0  crash at app.js:9\n" +
+		"        8 function crash() {\n" +
+		"     →  9     throw new Error();\n" +
+		"       10 }\n" +
+		"       11 \n" +
+		"  1  lib at lib.js:1\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -9,5 +9,10 @@ var (
 	helpStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	helpDisabledStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("239"))
 	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	warningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
 	savedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	inAppStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("14"))
+	frameStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	addrStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("5"))
+	badgeStyle = lipgloss.NewStyle().Bold(true).Padding(0, 1).Foreground(lipgloss.Color("0"))
 )
@@ -0,0 +1,266 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	lipgloss "charm.land/lipgloss/v2"
+	"github.com/getsentry/slope/envelope"
+	"github.com/getsentry/slope/envelope/event"
+)
+
+// formatSummary renders the triage summary of an event, transaction,
+// session or client report item, or returns an empty string for other items.
+func formatSummary(item envelope.Item) string {
+	switch item.Type {
+	case "event", "transaction":
+		return formatEventSummary(item)
+	case "session":
+		return formatSessionSummary(item)
+	case "sessions":
+		return formatSessionsSummary(item)
+	case "client_report":
+		return formatClientReportSummary(item)
+	}
+	return ""
+}
+
+// selectedSummary returns the summary of the selected item, as cached by
+// summarizeSelected.
+func (m Model) selectedSummary() string {
+	if s, ok := m.summaries[m.selected]; ok {
+		return s
+	}
+	return formatSummary(m.envelope.Items[m.selected])
+}
+
+// summarizeSelected renders the summary of the selected item, unless it is
+// cached, so that it is only rendered again after the item has changed.
+func (m *Model) summarizeSelected() {
+	if m.itemCount() == 0 {
+		return
+	}
+	if _, ok := m.summaries[m.selected]; !ok {
+		m.summaries[m.selected] = formatSummary(m.envelope.Items[m.selected])
+	}
+}
+
+func formatEventSummary(item envelope.Item) string {
+	ev, err := item.Event()
+	if err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	var title []string
+	if ev.Level != "" {
+		title = append(title, levelStyle(ev.Level).Render(ev.Level))
+	}
+	if t := eventTitle(ev); t != "" {
+		title = append(title, t)
+	}
+	if len(title) > 0 {
+		b.WriteString(strings.Join(title, " · ") + "\n")
+	}
+
+	b.WriteString(formatFields([]summaryField{
+		{"release", ev.Release},
+		{"environment", ev.Environment},
+		{"platform", ev.Platform},
+		{"sdk", formatSDK(ev.SDK)},
+		{"user", formatUser(ev.User)},
+		{"tags", formatTags(ev.Tags)},
+	}))
+	return b.String()
+}
+
+func formatSessionSummary(item envelope.Item) string {
+	s, err := item.Session()
+	if err != nil {
+		return ""
+	}
+	title := []string{sessionBadge(cmp.Or(s.Status, "ok"))}
+	if s.Errors == 1 {
+		title = append(title, "1 error")
+	} else {
+		title = append(title, fmt.Sprintf("%d errors", s.Errors))
+	}
+	if d, ok := s.Elapsed(); ok {
+		title = append(title, formatDuration(d))
+	}
+	if s.Init {
+		title = append(title, "init")
+	}
+
+	started := ""
+	if !s.Started.IsZero() {
+		started = absoluteTime(s.Started.Time)
+	}
+	return strings.Join(title, " · ") + "\n" + formatFields([]summaryField{
+		{"sid", s.SID},
+		{"did", string(s.DID)},
+		{"started", started},
+		{"release", s.Attrs.Release},
+		{"environment", s.Attrs.Environment},
+	})
+}
+
+func formatSessionsSummary(item envelope.Item) string {
+	a, err := item.SessionAggregates()
+	if err != nil {
+		return ""
+	}
+	var total event.SessionBucket
+	for _, bucket := range a.Aggregates {
+		total.Exited += bucket.Exited
+		total.Errored += bucket.Errored
+		total.Abnormal += bucket.Abnormal
+		total.Crashed += bucket.Crashed
+	}
+	title := []string{fmt.Sprintf("%d sessions in %d buckets", total.Total(), len(a.Aggregates))}
+	for _, count := range []struct {
+		status string
+		n      int
+	}{
+		{"exited", total.Exited},
+		{"errored", total.Errored},
+		{"abnormal", total.Abnormal},
+		{"crashed", total.Crashed},
+	} {
+		if count.n > 0 {
+			title = append(title, sessionBadge(count.status)+fmt.Sprintf(" %d", count.n))
+		}
+	}
+	return strings.Join(title, " · ") + "\n" + formatFields([]summaryField{
+		{"release", a.Attrs.Release},
+		{"environment", a.Attrs.Environment},
+	})
+}
+
+// formatClientReportSummary renders the discarded events of a client report
+// as a reason × category table.
+func formatClientReportSummary(item envelope.Item) string {
+	r, err := item.ClientReport()
+	if err != nil {
+		return ""
+	}
+	discarded := event.SumDiscardedEvents(r.DiscardedEvents)
+	if len(discarded) == 0 {
+		return helpStyle.Render("no discarded events") + "\n"
+	}
+
+	reasonWidth, categoryWidth, total := len("reason"), len("category"), 0
+	for _, d := range discarded {
+		reasonWidth = max(reasonWidth, len(d.Reason))
+		categoryWidth = max(categoryWidth, len(d.Category))
+		total += d.Quantity
+	}
+	quantityWidth := max(len("quantity"), len(fmt.Sprint(total)))
+
+	var b strings.Builder
+	row := func(reason, category, quantity string) string {
+		return fmt.Sprintf("%-*s  %-*s  %*s", reasonWidth, reason, categoryWidth, category, quantityWidth, quantity)
+	}
+	b.WriteString(helpStyle.Render(row("reason", "category", "quantity")) + "\n")
+	for _, d := range discarded {
+		b.WriteString(row(d.Reason, d.Category, fmt.Sprint(d.Quantity)) + "\n")
+	}
+	b.WriteString(labelStyle.Render(row("", "total", fmt.Sprint(total))) + "\n")
+	return b.String()
+}
+
+func sessionBadge(status string) string {
+	color := "2"
+	switch status {
+	case "crashed":
+		color = "9"
+	case "abnormal", "errored":
+		color = "11"
+	case "exited":
+		color = "4"
+	}
+	return badgeStyle.Background(lipgloss.Color(color)).Render(strings.ToUpper(status))
+}
+
+type summaryField struct {
+	label, value string
+}
+
+// formatFields renders labelled values, skipping empty ones.
+func formatFields(fields []summaryField) string {
+	var b strings.Builder
+	for _, field := range fields {
+		if field.value != "" {
+			b.WriteString(helpStyle.Render(fmt.Sprintf("%-12s", field.label)) + field.value + "\n")
+		}
+	}
+	return b.String()
+}
+
+// eventTitle returns the transaction name, message or most recent
+// exception of an event.
+func eventTitle(ev *event.Event) string {
+	switch {
+	case ev.Type == "transaction" && ev.Transaction != "":
+		return ev.Transaction
+	case len(ev.Exception) > 0:
+		return exceptionTitle(ev.Exception[len(ev.Exception)-1])
+	}
+	for _, msg := range []*event.Message{ev.Message, ev.Logentry} {
+		if msg == nil {
+			continue
+		}
+		if msg.Formatted != "" {
+			return msg.Formatted
+		}
+		if msg.Message != "" {
+			return msg.Message
+		}
+	}
+	return ev.Transaction
+}
+
+func formatSDK(sdk *event.SDK) string {
+	if sdk == nil {
+		return ""
+	}
+	return strings.TrimSpace(sdk.Name + " " + sdk.Version)
+}
+
+func formatUser(u *event.User) string {
+	if u == nil {
+		return ""
+	}
+	var parts []string
+	for _, s := range []string{u.Username, u.Name} {
+		if s != "" {
+			parts = append(parts, s)
+			break
+		}
+	}
+	if u.Email != "" {
+		parts = append(parts, "<"+u.Email+">")
+	}
+	if u.ID != "" {
+		parts = append(parts, "id "+string(u.ID))
+	}
+	if u.IPAddress != "" {
+		parts = append(parts, u.IPAddress)
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatTags(tags event.Tags) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + tags[k]
+	}
+	return strings.Join(pairs, ", ")
+}
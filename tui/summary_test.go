@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/getsentry/slope/envelope"
+)
+
+func TestFormatSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     string
+		payload string
+		want    string
+	}{
+		{
+			"crash",
+			"event",
+			`{"level":"fatal","platform":"native","release":"app@1.2.3","environment":"production",` +
+				`"exception":{"values":[{"type":"IOError"},{"type":"SIGSEGV","value":"Segfault"}]},` +
+				`"sdk":{"name":"sentry.native","version":"0.11.2"},"user":{"username":"nobody","email":"nobody@example.com"},` +
+				`"tags":{"backend":"inproc","arch":"x86_64"}}`,
+			"fatal · SIGSEGV: Segfault\n" +
+				"release     app@1.2.3\n" +
+				"environment production\n" +
+				"platform    native\n" +
+				"sdk         sentry.native 0.11.2\n" +
+				"user        nobody <nobody@example.com>\n" +
+				"tags        arch=x86_64, backend=inproc\n",
+		},
+		{
+			"message",
+			"event",
+			`{"level":"info","message":{"formatted":"hello world"},"user":{"id":42}}`,
+			"info · hello world\n" +
+				"user        id 42\n",
+		},
+		{
+			"transaction",
+			"transaction",
+			`{"type":"transaction","transaction":"GET /api","platform":"python"}`,
+			"GET /api\n" +
+				"platform    python\n",
+		},
+		{
+			"session",
+			"session",
+			`{"init":true,"sid":"e2b6cc4c-8a71-4a4c-b1d9-5ba1db7a0a53","status":"crashed","did":"42","errors":1,` +
+				`"started":"2025-10-09T08:05:03.849779Z","duration":1.070484,"attrs":{"release":"app@1.2.3","environment":"development"}}`,
+			" CRASHED  · 1 error · 1.07s · init\n" +
+				"sid         e2b6cc4c-8a71-4a4c-b1d9-5ba1db7a0a53\n" +
+				"did         42\n" +
+				"started     2025-10-09 08:05:03.849\n" +
+				"release     app@1.2.3\n" +
+				"environment development\n",
+		},
+		{
+			"session update",
+			"session",
+			`{"sid":"e2b6cc4c-8a71-4a4c-b1d9-5ba1db7a0a53","errors":2}`,
+			" OK  · 2 errors\n" +
+				"sid         e2b6cc4c-8a71-4a4c-b1d9-5ba1db7a0a53\n",
+		},
+		{
+			"sessions",
+			"sessions",
+			`{"aggregates":[{"started":"2025-10-09T08:00:00Z","exited":3,"crashed":1},{"started":"2025-10-09T08:01:00Z","exited":2}],"attrs":{"release":"app@1.2.3"}}`,
+			"6 sessions in 2 buckets ·  EXITED  5 ·  CRASHED  1\n" +
+				"release     app@1.2.3\n",
+		},
+		{
+			"client report",
+			"client_report",
+			`{"discarded_events":[{"reason":"queue_overflow","category":"error","quantity":2},` +
+				`{"reason":"sample_rate","category":"transaction","quantity":10},{"reason":"queue_overflow","category":"error","quantity":1}]}`,
+			"reason          category     quantity\n" +
+				"sample_rate     transaction        10\n" +
+				"queue_overflow  error               3\n" +
+				"                total              13\n",
+		},
+		{"empty client report", "client_report", `{"discarded_events":[]}`, "no discarded events\n"},
+		{"attachment", "attachment", `{"level":"fatal"}`, ""},
+		{"invalid", "event", `not json`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := envelope.Item{Type: tt.typ, Payload: []byte(tt.payload)}
+			if got := ansi.Strip(formatSummary(item)); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewSummary(t *testing.T) {
+	m := testModel(1)
+	m.envelope.Add(envelope.Item{
+		Header:  json.RawMessage(`{"type":"event"}`),
+		Payload: []byte(`{"level":"error","message":"boom","release":"app@1.0"}`),
+		Type:    "event",
+	})
+	if v := ansi.Strip(viewText(m)); strings.Contains(v, "release") {
+		t.Errorf("empty event should have no summary, got:\n%s", v)
+	}
+	m = update(m, key('j'))
+	v := ansi.Strip(viewText(m))
+	if !strings.Contains(v, "error · boom") || !strings.Contains(v, "release     app@1.0") {
+		t.Errorf("view should contain summary, got:\n%s", v)
+	}
+}
+
+func TestViewSummaryCache(t *testing.T) {
+	m := testModel(2)
+	m.envelope.Items[1].Payload = []byte(`{"message":"boom"}`)
+	m = update(m, key('j'))
+	if s, ok := m.summaries[1]; !ok || !strings.Contains(s, "boom") {
+		t.Fatalf("summary should be cached on selection, got %q", s)
+	}
+	clear(m.summaries)
+	if v := ansi.Strip(viewText(m)); !strings.Contains(v, "boom") {
+		t.Errorf("view should contain summary, got:\n%s", v)
+	}
+	if len(m.summaries) != 0 {
+		t.Error("view should not cache summaries")
+	}
+	m = update(m, editResultMsg{index: 1, payload: []byte(`{"message":"bang"}`)})
+	if v := ansi.Strip(viewText(m)); !strings.Contains(v, "bang") || strings.Contains(v, "boom") {
+		t.Errorf("view should contain the edited summary, got:\n%s", v)
+	}
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/getsentry/slope/symbolicate"
+)
+
+// SetSymbolicator sets the debug directories, mapping files and source maps
+// to symbolicate events with.
+func (m *Model) SetSymbolicator(s *symbolicate.Symbolicator) {
+	m.symbolicator = s
+}
+
+// canSymbolicate reports whether the selected item is an event.
+func (m Model) canSymbolicate() bool {
+	if m.itemCount() == 0 {
+		return false
+	}
+	typ := m.envelope.Items[m.selected].Type
+	return typ == "event" || typ == "transaction"
+}
+
+// symbolicateSelected symbolicates the selected event in place.
+func (m Model) symbolicateSelected() (tea.Model, tea.Cmd) {
+	if m.symbolicator == nil || !m.symbolicator.Enabled() {
+		m.message = errorStyle.Render("Nothing to symbolicate with, start slope with --debug-dir, --mapping or --source-maps")
+		return m, nil
+	}
+	item := &m.envelope.Items[m.selected]
+	ev, err := item.Event()
+	if err != nil {
+		m.message = errorStyle.Render("Error: " + err.Error())
+		return m, nil
+	}
+	stats, err := m.symbolicator.Symbolicate(ev)
+	if err != nil {
+		m.message = errorStyle.Render("Error: " + err.Error())
+		return m, nil
+	}
+	m.message = formatSymbolicateStats(stats)
+	if stats.Symbolicated == 0 {
+		return m, nil
+	}
+	if err := item.SetEvent(ev); err != nil {
+		m.message = errorStyle.Render("Error: " + err.Error())
+		return m, nil
+	}
+	m.itemsChanged()
+	m.message = savedStyle.Render(m.message)
+	return m, m.printDump()
+}
+
+func formatSymbolicateStats(stats symbolicate.Stats) string {
+	msg := fmt.Sprintf("Symbolicated %d of %d frames", stats.Symbolicated, stats.Frames)
+	switch n := len(stats.Missing); n {
+	case 0:
+	case 1:
+		msg += " · no debug file for " + imageName(stats.Missing[0])
+	default:
+		msg += fmt.Sprintf(" · no debug files for %d images", n)
+	}
+	return msg
+}
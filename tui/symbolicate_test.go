@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/getsentry/slope/envelope/event"
+	"github.com/getsentry/slope/symbolicate"
+)
+
+func TestSymbolicateSelected(t *testing.T) {
+	ev := `{"exception":{"values":[{"stacktrace":{"frames":[{"instruction_addr":"0x1114a"}]}}]},` +
+		`"debug_meta":{"images":[{"type":"elf","code_file":"/usr/bin/crash",` +
+		`"code_id":"89a79abce0b8c4c8e628e26bc0b8e10f38a90b1a","image_addr":"0x10000","image_size":16384}]}}`
+	m := testModel(1)
+	m.envelope.Items[0].Payload = []byte(ev)
+
+	m = update(m, key('s'))
+	if !strings.Contains(m.message, "--debug-dir") || m.dirty {
+		t.Errorf("without debug dirs: message = %q, dirty = %v", m.message, m.dirty)
+	}
+
+	m.SetSymbolicator(&symbolicate.Symbolicator{DebugDirs: []string{"../symbolicate/testdata"}})
+	m = update(m, key('s'))
+	if got := ansi.Strip(m.message); got != "Symbolicated 1 of 1 frames" {
+		t.Errorf("message = %q", got)
+	}
+	if !m.dirty {
+		t.Error("dirty = false, want true")
+	}
+	symbolicated, err := m.envelope.Items[0].Event()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if frames := symbolicated.Exception[0].Stacktrace.Frames; len(frames) != 3 || frames[2].Function != "write_value" {
+		t.Errorf("frames = %+v", frames)
+	}
+
+	// Frames with line numbers are not symbolicated again
+	m.dirty = false
+	m = update(m, key('s'))
+	if got := ansi.Strip(m.message); got != "Symbolicated 0 of 0 frames" || m.dirty {
+		t.Errorf("again: message = %q, dirty = %v", got, m.dirty)
+	}
+}
+
+func TestSymbolicateSelectedMapping(t *testing.T) {
+	m := testModel(1)
+	m.envelope.Items[0].Payload = []byte(`{"exception":{"values":[{"type":"b","module":"a","stacktrace":{"frames":[{"module":"a.a","function":"b","lineno":3}]}}]},` +
+		`"debug_meta":{"images":[{"type":"proguard","uuid":"ea338d3c-b49c-5411-a9b6-9aabb39e062a"}]}}`)
+	m.SetSymbolicator(&symbolicate.Symbolicator{Mappings: []string{"../symbolicate/testdata/mapping.txt"}})
+
+	m = update(m, key('s'))
+	if got := ansi.Strip(m.message); got != "Symbolicated 1 of 1 frames" || !m.dirty {
+		t.Errorf("message = %q, dirty = %v", got, m.dirty)
+	}
+	ev, err := m.envelope.Items[0].Event()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f := ev.Exception[0].Stacktrace.Frames[0]; f.Function != "throwNested" || f.Lineno != 21 {
+		t.Errorf("frame = %+v", f)
+	}
+}
+
+func TestSymbolicateSelectedSourceMaps(t *testing.T) {
+	m := testModel(1)
+	m.envelope.Items[0].Payload = []byte(`{"exception":{"values":[{"type":"Error","stacktrace":{"frames":[` +
+		`{"function":"t","abs_path":"https://example.com/static/app.min.js","lineno":1,"colno":46}]}}]}}`)
+	m.SetSymbolicator(&symbolicate.Symbolicator{SourceMaps: []string{"~/static=../symbolicate/testdata/sourcemaps/static"}})
+
+	m = update(m, key('s'))
+	if got := ansi.Strip(m.message); got != "Symbolicated 1 of 1 frames" || !m.dirty {
+		t.Errorf("message = %q, dirty = %v", got, m.dirty)
+	}
+	ev, err := m.envelope.Items[0].Event()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f := ev.Exception[0].Stacktrace.Frames[0]; f.Function != "crash" || f.Lineno != 6 || len(f.PreContext) != 5 {
+		t.Errorf("frame = %+v", f)
+	}
+}
+
+func TestFormatSymbolicateStats(t *testing.T) {
+	libc := event.DebugImage{CodeFile: "/usr/lib/libc.so.6"}
+	tests := []struct {
+		stats symbolicate.Stats
+		want  string
+	}{
+		{symbolicate.Stats{Frames: 3, Symbolicated: 3}, "Symbolicated 3 of 3 frames"},
+		{symbolicate.Stats{Frames: 3, Symbolicated: 2, Missing: []event.DebugImage{libc}}, "Symbolicated 2 of 3 frames · no debug file for libc.so.6"},
+		{symbolicate.Stats{Frames: 3, Missing: []event.DebugImage{libc, libc}}, "Symbolicated 0 of 3 frames · no debug files for 2 images"},
+		{symbolicate.Stats{Frames: 1, Missing: []event.DebugImage{{Type: "proguard", UUID: "ea338d3c-b49c-5411-a9b6-9aabb39e062a"}}},
+			"Symbolicated 0 of 1 frames · no debug file for proguard ea338d3c-b49c-5411-a9b6-9aabb39e062a"},
+	}
+	for _, tt := range tests {
+		if got := formatSymbolicateStats(tt.stats); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	lipgloss "charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/getsentry/slope/envelope/event"
+)
+
+type spanRow struct {
+	span  event.Span
+	depth int
+}
+
+// spanTree orders spans depth first by parent_span_id, siblings by start
+// time. Spans whose parent is unknown are roots, and so are spans that are
+// not reached from one, such as in parent cycles.
+func spanTree(spans []event.Span) []spanRow {
+	ids := map[string]bool{}
+	for _, s := range spans {
+		if s.SpanID != "" {
+			ids[s.SpanID] = true
+		}
+	}
+	children := map[string][]int{}
+	var roots []int
+	for i, s := range spans {
+		if s.ParentSpanID != "" && ids[s.ParentSpanID] && s.ParentSpanID != s.SpanID {
+			children[s.ParentSpanID] = append(children[s.ParentSpanID], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	var rows []spanRow
+	added := make([]bool, len(spans))
+	expanded := map[string]bool{}
+	var walk func(level []int, depth int)
+	walk = func(level []int, depth int) {
+		slices.SortStableFunc(level, func(a, b int) int {
+			return spans[a].StartTimestamp.Compare(spans[b].StartTimestamp.Time)
+		})
+		for _, i := range level {
+			if added[i] {
+				continue
+			}
+			added[i] = true
+			s := spans[i]
+			rows = append(rows, spanRow{s, depth})
+			if s.SpanID != "" && !expanded[s.SpanID] {
+				expanded[s.SpanID] = true
+				walk(children[s.SpanID], depth+1)
+			}
+		}
+	}
+	walk(roots, 0)
+	if len(rows) < len(spans) {
+		rest := make([]int, len(spans))
+		for i := range rest {
+			rest[i] = i
+		}
+		walk(rest, 0)
+	}
+	return rows
+}
+
+// formatWaterfall renders spans as a tree with a bar for each span on a
+// common time axis.
+func formatWaterfall(spans []event.Span, width int) string {
+	if len(spans) == 0 {
+		return helpStyle.Render("(no spans)")
+	}
+	rows := spanTree(spans)
+
+	var start, end time.Time
+	statusWidth := 0
+	for _, row := range rows {
+		s := row.span
+		if !s.StartTimestamp.IsZero() && (start.IsZero() || s.StartTimestamp.Before(start)) {
+			start = s.StartTimestamp.Time
+		}
+		if e := s.End(); !e.IsZero() && e.After(end) {
+			end = e.Time
+		}
+		statusWidth = max(statusWidth, len(s.Status))
+	}
+	total := end.Sub(start)
+
+	labelWidth := min(48, max(width/2, 20))
+	durationWidth := 9
+	barWidth := width - labelWidth - durationWidth - statusWidth - 3
+	if total <= 0 || start.IsZero() {
+		barWidth = 0
+	}
+
+	var b strings.Builder
+	if barWidth >= 10 {
+		axisEnd := formatDuration(total)
+		axis := "0" + strings.Repeat(" ", max(barWidth-1-len(axisEnd), 1)) + axisEnd
+		b.WriteString(strings.Repeat(" ", labelWidth+durationWidth+statusWidth+3) + helpStyle.Render(axis) + "\n")
+	}
+	for _, row := range rows {
+		s := row.span
+		label := strings.Repeat("  ", row.depth) + labelStyle.Render(cmp.Or(s.Op, "span"))
+		if desc := s.Label(); desc != "" {
+			label += " " + desc
+		}
+		label = ansi.Truncate(label, labelWidth, "…")
+		label += strings.Repeat(" ", labelWidth-ansi.StringWidth(label))
+
+		duration := "?"
+		if !s.StartTimestamp.IsZero() && !s.End().IsZero() {
+			duration = formatDuration(s.End().Sub(s.StartTimestamp.Time))
+		}
+		line := fmt.Sprintf("%s %*s", label, durationWidth, duration)
+		if statusWidth > 0 {
+			line += " " + spanStatusStyle(s.Status).Render(fmt.Sprintf("%-*s", statusWidth, s.Status))
+		}
+		if barWidth >= 10 {
+			line += " " + spanBar(s, start, total, barWidth)
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func spanBar(s event.Span, start time.Time, total time.Duration, width int) string {
+	if s.StartTimestamp.IsZero() || s.End().IsZero() {
+		return ""
+	}
+	scale := func(d time.Duration) int {
+		return int(float64(d) / float64(total) * float64(width))
+	}
+	offset := min(scale(s.StartTimestamp.Sub(start)), width-1)
+	length := min(max(scale(s.End().Sub(s.StartTimestamp.Time)), 1), width-offset)
+	return strings.Repeat(" ", offset) + spanStatusStyle(s.Status).Render(strings.Repeat("█", length))
+}
+
+func spanStatusStyle(status string) lipgloss.Style {
+	switch status {
+	case "", "ok":
+		return savedStyle
+	case "cancelled", "unknown", "unknown_error":
+		return warningStyle
+	}
+	return errorStyle
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	case d < time.Minute:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/getsentry/slope/envelope/event"
+)
+
+const transactionEvent = `{"type":"transaction","transaction":"GET /api","start_timestamp":1760000000,"timestamp":1760000001,` +
+	`"contexts":{"trace":{"trace_id":"ba5b92f3d5414dff096ddce6d7e66fa1","span_id":"root","op":"http.server","status":"ok"}},` +
+	`"spans":[` +
+	`{"span_id":"c","parent_span_id":"b","op":"db","description":"SELECT 1","start_timestamp":1760000000.5,"timestamp":1760000000.75,"status":"internal_error"},` +
+	`{"span_id":"b","parent_span_id":"root","op":"function","description":"load","start_timestamp":1760000000.25,"timestamp":1760000001},` +
+	`{"span_id":"a","parent_span_id":"root","op":"middleware","start_timestamp":1760000000,"timestamp":1760000000.25}]}`
+
+func TestSpanTree(t *testing.T) {
+	ev := parseEvent(t, transactionEvent)
+	rows := spanTree(append([]event.Span{*ev.RootSpan()}, ev.Spans...))
+	var got []string
+	for _, row := range rows {
+		got = append(got, strings.Repeat(".", row.depth)+row.span.SpanID)
+	}
+	if want := "root .a .b ..c"; strings.Join(got, " ") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestSpanTreeOrphansAndCycles(t *testing.T) {
+	rows := spanTree([]event.Span{
+		{SpanID: "a", ParentSpanID: "b"},
+		{SpanID: "b", ParentSpanID: "a"},
+		{SpanID: "c", ParentSpanID: "missing"},
+		{SpanID: "d", ParentSpanID: "d"},
+		{SpanID: "e", ParentSpanID: "c"},
+		{SpanID: "e", ParentSpanID: "c"},
+	})
+	var got []string
+	for _, row := range rows {
+		got = append(got, strings.Repeat(".", row.depth)+row.span.SpanID)
+	}
+	if want := "c .e .e d a .b"; strings.Join(got, " ") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestFormatWaterfall(t *testing.T) {
+	ev := parseEvent(t, transactionEvent)
+	got := ansi.Strip(formatWaterfall(append([]event.Span{*ev.RootSpan()}, ev.Spans...), 80))
+	want := "                                                                  0        1.00s\n" +
+		"http.server GET /api                         1.00s ok             ██████████████\n" +
+		"  middleware                               250.0ms                ███\n" +
+		"  function load                            750.0ms                   ██████████\n" +
+		"    db SELECT 1                            250.0ms internal_error        ███"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := ansi.Strip(formatWaterfall([]event.Span{{Op: "db"}}, 80)); got != "db"+strings.Repeat(" ", 47)+"?" {
+		t.Errorf("untimed span: got %q", got)
+	}
+	if got := formatWaterfall(nil, 80); !strings.Contains(got, "no spans") {
+		t.Errorf("no spans: got %q", got)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{1500 * time.Microsecond, "1.5ms"},
+		{1234 * time.Millisecond, "1.23s"},
+		{90 * time.Second, "1m30s"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}